@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// asciidoc emits AsciiDoc as understood by Asciidoctor and Antora.
+type asciidoc struct{}
+
+var (
+	adocEscaper     = strings.NewReplacer(`\`, `\\`, "{", `\{`, "*", `\*`, "`", "\\`", "#", `\#`)
+	adocCellEscaper = strings.NewReplacer("|", `\|`, "\r\n", " +\n", "\n", " +\n")
+	adocPipeEscaper = strings.NewReplacer("|", `\|`)
+)
+
+func (asciidoc) title(b *bytes.Buffer, title string) {
+	fmt.Fprintf(b, "= %s\n", adocEscaper.Replace(title))
+}
+
+func (asciidoc) heading(b *bytes.Buffer, level int, title, anchor string) {
+	fmt.Fprintf(b, "\n[[%s]]\n%s %s\n", targetID(anchor), strings.Repeat("=", level+1), adocEscaper.Replace(title))
+}
+
+func (a asciidoc) text(t Text) string {
+	if t.Link != "" {
+		link := t.Link
+		t.Link = ""
+		return "link:" + link + "[" + a.text(t) + "]"
+	}
+	if t.Strike {
+		t.Strike = false
+		return "[.line-through]#" + a.text(t) + "#"
+	}
+	if t.Strong {
+		return "**" + adocEscaper.Replace(t.Value) + "**"
+	}
+	if !t.Code {
+		return adocEscaper.Replace(t.Value)
+	}
+	// Literal monospace; the passthrough keeps the value free of substitutions.
+	if strings.Contains(t.Value, "+") {
+		return "`++" + t.Value + "++`"
+	}
+	return "`+" + t.Value + "+`"
+}
+
+func (a asciidoc) paragraph(b *bytes.Buffer, p Paragraph) {
+	fmt.Fprintf(b, "\n%s\n", joinText(p, a.text))
+}
+
+func (a asciidoc) list(b *bytes.Buffer, l List) {
+	b.WriteString("\n")
+	for _, item := range l {
+		fmt.Fprintf(b, "* %s\n", joinText(item, a.text))
+	}
+}
+
+func (a asciidoc) contents(b *bytes.Buffer, c Contents) {
+	b.WriteString("\n")
+	a.contentsList(b, c, "*")
+}
+
+func (a asciidoc) contentsList(b *bytes.Buffer, c Contents, marker string) {
+	for _, e := range c {
+		fmt.Fprintf(b, "%s <<%s,%s>>\n", marker, targetID(e.Anchor), adocEscaper.Replace(e.Title))
+		a.contentsList(b, e.Entries, marker+"*")
+	}
+}
+
+func (a asciidoc) table(b *bytes.Buffer, t *Table) {
+	cols := make([]string, 0, len(t.Columns))
+	for _, c := range t.Columns {
+		if c.Align == AlignCenter {
+			cols = append(cols, "^")
+		} else {
+			cols = append(cols, "<")
+		}
+	}
+	fmt.Fprintf(b, "\n[cols=\"%s\",options=\"header\"]\n|===\n", strings.Join(cols, ","))
+	header := make([]string, 0, len(t.Columns))
+	for _, c := range t.Columns {
+		header = append(header, "|"+adocCellEscaper.Replace(adocEscaper.Replace(c.Title)))
+	}
+	fmt.Fprintf(b, "%s\n", strings.Join(header, " "))
+	for _, row := range t.Rows {
+		b.WriteString("\n")
+		for _, cell := range row.Cells {
+			style, content := a.cell(cell)
+			fmt.Fprintf(b, "%s|%s\n", style, content)
+		}
+	}
+	b.WriteString("|===\n")
+}
+
+// cell renders a table cell and returns its style. Monospace text cannot
+// span lines, so code of several lines goes in a listing block of an
+// AsciiDoc cell.
+func (a asciidoc) cell(c Cell) (string, string) {
+	style := ""
+	var b strings.Builder
+	for _, t := range c {
+		if t.Code && strings.Contains(t.Value, "\n") {
+			style = "a"
+			fmt.Fprintf(&b, "\n\n----\n%s\n----\n\n", adocPipeEscaper.Replace(t.Value))
+			continue
+		}
+		b.WriteString(adocCellEscaper.Replace(a.text(t)))
+	}
+	return style, b.String()
+}
+
+func (asciidoc) code(b *bytes.Buffer, c CodeBlock) {
+	b.WriteString("\n")
+	if c.Lang != "" {
+		fmt.Fprintf(b, "[source,%s]\n", c.Lang)
+	}
+	fmt.Fprintf(b, "----\n%s\n----\n", c.Code)
+}
+
+// diagram emits an Asciidoctor Diagram block.
+func (asciidoc) diagram(b *bytes.Buffer, d *Diagram) {
+	fmt.Fprintf(b, "\n[mermaid]\n....\n%s\n....\n", d.Mermaid())
+}
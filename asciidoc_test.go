@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAsciidocText(t *testing.T) {
+	tests := []struct {
+		text Text
+		want string
+	}{
+		{Text{Value: "a *b* `c` #d {e}"}, "a \\*b\\* \\`c\\` \\#d \\{e}"},
+		{Text{Value: "bold", Strong: true}, "**bold**"},
+		{Text{Value: "old", Strike: true}, "[.line-through]#old#"},
+		{Text{Value: "var.x", Code: true}, "`+var.x+`"},
+		{Text{Value: "a + b", Code: true}, "`++a + b++`"},
+		{Text{Value: "docs", Link: "https://example.com"}, "link:https://example.com[docs]"},
+		{Text{Value: "docs", Link: "https://example.com", Strong: true}, "link:https://example.com[**docs**]"},
+	}
+	for _, tt := range tests {
+		if got := (asciidoc{}).text(tt.text); got != tt.want {
+			t.Errorf("text(%+v) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestAsciidocRender(t *testing.T) {
+	var b bytes.Buffer
+	if err := renderers["asciidoc"].Render(&b, sampleDocument()); err != nil {
+		t.Fatal(err)
+	}
+	want := `= my_\*module\*
+
+[[usage-notes]]
+== \` + "`Usage\\`" + ` \*notes\*
+
+* <<id-launch,🚀 Launch>>
+* <<input,Input>>
+
+[[id-launch]]
+== 🚀 Launch
+
+Run ` + "`+terraform apply+`" + `.
+
+[[input]]
+== Input
+
+[cols="<,^",options="header"]
+|===
+|Name |Required
+
+|` + "`+region+`" + `
+|yes
+
+|a\|b
+a|
+
+----
+{
+  a = 1
+}
+----
+
+
+|===
+
+[[id-2024-notes]]
+=== 2024 notes
+
+* **Strong**
+* link:https://example.com[docs]
+
+[source,hcl]
+----
+x = 1
+----
+`
+	if got := b.String(); got != want {
+		t.Errorf("asciidoc = %s, want %s", got, want)
+	}
+}
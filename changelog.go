@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// semverTag matches release tags such as v1.2.3 or 1.2.3-rc.1.
+var semverTag = regexp.MustCompile(`^v?(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z.-]+))?$`)
+
+// release is a semver tag of the repository.
+type release struct {
+	Tag     string
+	Date    string
+	Version [3]int
+	Pre     string
+}
+
+// less orders releases by semantic version; pre-releases come before the
+// release they precede.
+func (r release) less(o release) bool {
+	for i := range r.Version {
+		if r.Version[i] != o.Version[i] {
+			return r.Version[i] < o.Version[i]
+		}
+	}
+	if r.Pre == "" || o.Pre == "" {
+		return r.Pre != "" && o.Pre == ""
+	}
+	return preReleaseLess(r.Pre, o.Pre)
+}
+
+// preReleaseLess orders pre-release labels as semver does: dot-separated
+// identifiers are compared in turn, numerically if both are numeric, with
+// numeric identifiers before alphanumeric ones, and a label that is a
+// prefix of the other comes first. rc.9 comes before rc.10.
+func preReleaseLess(a, b string) bool {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if as[i] == bs[i] {
+			continue
+		}
+		an, aErr := strconv.ParseUint(as[i], 10, 64)
+		bn, bErr := strconv.ParseUint(bs[i], 10, 64)
+		switch {
+		case aErr == nil && bErr == nil:
+			return an < bn
+		case aErr == nil || bErr == nil:
+			return aErr == nil
+		}
+		return as[i] < bs[i]
+	}
+	return len(as) < len(bs)
+}
+
+// releaseTags returns the repository's semver tags in version order.
+func releaseTags() ([]release, error) {
+	out, err := git("for-each-ref", "--format=%(refname:short) %(creatordate:short)", "refs/tags")
+	if err != nil {
+		return nil, err
+	}
+	var releases []release
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		m := semverTag.FindStringSubmatch(fields[0])
+		if m == nil {
+			continue
+		}
+		r := release{Tag: fields[0], Date: fields[1], Pre: m[4]}
+		for i := range r.Version {
+			r.Version[i], _ = strconv.Atoi(m[i+1])
+		}
+		releases = append(releases, r)
+	}
+	sort.Slice(releases, func(i, j int) bool { return releases[i].less(releases[j]) })
+	return releases, nil
+}
+
+// changeKindTitles are the changelog sub-headings of each kind of change.
+var changeKindTitles = []struct {
+	Kind  ChangeKind
+	Title string
+}{
+	{Added, "Added"},
+	{Removed, "Removed"},
+	{Changed, "Changed"},
+}
+
+// releaseSection lists the changes of a release by kind, breaking changes
+// first and highlighted.
+func releaseSection(r release, changes []Change, initial bool) *Section {
+	s := &Section{Title: fmt.Sprintf("%s (%s)", r.Tag, r.Date)}
+	switch {
+	case initial:
+		s.Blocks = append(s.Blocks, Paragraph{{Value: "Initial release."}})
+		return s
+	case len(changes) == 0:
+		s.Blocks = append(s.Blocks, Paragraph{{Value: "No interface changes."}})
+		return s
+	}
+	for _, kt := range changeKindTitles {
+		var list List
+		for _, major := range []bool{true, false} {
+			for _, c := range changes {
+				if c.Kind != kt.Kind || (c.Bump == BumpMajor) != major {
+					continue
+				}
+				item := codeSpans(c.Description)
+				if major {
+					item = append(Paragraph{{Value: "BREAKING:", Strong: true}, {Value: " "}}, item...)
+				}
+				list = append(list, item)
+			}
+		}
+		if len(list) > 0 {
+			s.Blocks = append(s.Blocks, &Section{Title: kt.Title, Blocks: []Block{list}})
+		}
+	}
+	return s
+}
+
+// defaultChangelogIntro starts a new changelog file.
+const defaultChangelogIntro = "# Changelog\n\nAll notable changes to the interface of this module are documented here.\n"
+
+// changelogSection is a release section of an existing changelog.
+type changelogSection struct {
+	Tag  string
+	Text string
+}
+
+// parseChangelog splits an existing changelog into the part before the
+// first release section and the release sections, which are kept verbatim
+// when the file is updated. The tag of a section is the first word of its
+// heading.
+func parseChangelog(existing []byte) (string, []changelogSection) {
+	if len(existing) == 0 {
+		return defaultChangelogIntro, nil
+	}
+	var (
+		intro    string
+		sections []changelogSection
+		start    = -1
+	)
+	lines := strings.SplitAfter(string(existing), "\n")
+	for i := 0; i <= len(lines); i++ {
+		if i < len(lines) && !strings.HasPrefix(lines[i], "## ") {
+			continue
+		}
+		if start < 0 {
+			intro = strings.Join(lines[:i], "")
+		} else {
+			s := changelogSection{Text: strings.Join(lines[start:i], "")}
+			if fields := strings.Fields(lines[start]); len(fields) > 1 {
+				s.Tag = strings.Trim(fields[1], "[]")
+			}
+			sections = append(sections, s)
+		}
+		start = i
+	}
+	return intro, sections
+}
+
+// mergeChangelog inserts the sections of new releases, given newest first,
+// among the existing sections: each goes before the first existing section
+// of an older release. order gives the position of each tag in version
+// order; existing sections of other tags, such as an Unreleased section,
+// stay where they are.
+func mergeChangelog(existing, added []changelogSection, order map[string]int) []changelogSection {
+	merged := make([]changelogSection, 0, len(existing)+len(added))
+	for _, s := range existing {
+		if i, ok := order[s.Tag]; ok {
+			for len(added) > 0 && order[added[0].Tag] > i {
+				merged = append(merged, added[0])
+				added = added[1:]
+			}
+		}
+		merged = append(merged, s)
+	}
+	return append(merged, added...)
+}
+
+// runChangelog implements the changelog command.
+func runChangelog(args []string) {
+	fs := flag.NewFlagSet("changelog", flag.ExitOnError)
+	var (
+		dir     = fs.String("path", ".", "module directory")
+		out     = fs.String("o", "CHANGELOG.md", `changelog file to write or update, "-" for stdout`)
+		verbose = fs.Bool("v", false, "verbose mode")
+	)
+	_ = fs.Parse(args)
+
+	releases, err := releaseTags()
+	if err != nil {
+		log.Fatalf("Error listing tags: %s.", err)
+	}
+
+	var existing []byte
+	if *out != "-" {
+		if existing, err = ioutil.ReadFile(*out); err != nil && !os.IsNotExist(err) {
+			log.Fatalf("Error reading %q: %s.", *out, err)
+		}
+	}
+	intro, kept := parseChangelog(existing)
+	have := make(map[string]bool, len(kept))
+	for _, s := range kept {
+		have[s.Tag] = true
+	}
+
+	var added []changelogSection
+	order := make(map[string]int, len(releases))
+	var prev *Module
+	for i, r := range releases {
+		order[r.Tag] = i
+		src := gitSource{Ref: r.Tag, Dir: *dir}
+		ok, err := src.exists()
+		if err != nil {
+			log.Fatalf("Error reading %s: %s.", src, err)
+		}
+		if !ok {
+			// A tag from before the module existed.
+			if *verbose {
+				log.Printf("Skipping %s: no module directory.", r.Tag)
+			}
+			continue
+		}
+		m, err := loadModule(src)
+		if err != nil {
+			log.Fatalf("Error loading module %s: %s.", src, err)
+		}
+		if !have[r.Tag] {
+			var changes []Change
+			if prev != nil {
+				changes = diffModules(prev, m)
+			}
+			var b bytes.Buffer
+			doc := &Document{Sections: []*Section{releaseSection(r, changes, prev == nil)}}
+			if err := renderers["markdown"].Render(&b, doc); err != nil {
+				log.Fatalf("Error rendering changelog: %s.", err)
+			}
+			added = append([]changelogSection{{Tag: r.Tag, Text: b.String()}}, added...)
+		} else if *verbose {
+			log.Printf("Keeping the existing section of %s.", r.Tag)
+		}
+		prev = m
+	}
+
+	var b bytes.Buffer
+	b.WriteString(strings.TrimRight(intro, "\n") + "\n")
+	for _, s := range mergeChangelog(kept, added, order) {
+		b.WriteString("\n" + strings.Trim(s.Text, "\n") + "\n")
+	}
+
+	if *out == "-" {
+		_, err = b.WriteTo(os.Stdout)
+	} else {
+		err = errors.Wrap(ioutil.WriteFile(*out, b.Bytes(), 0644), "write file")
+	}
+	if err != nil {
+		log.Fatalf("Error writing changelog: %s.", err)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+func TestReleaseLess(t *testing.T) {
+	// Tags in semver precedence order.
+	tags := []string{
+		"v1.0.0-alpha",
+		"v1.0.0-alpha.1",
+		"v1.0.0-alpha.beta",
+		"v1.0.0-beta",
+		"v1.0.0-beta.2",
+		"v1.0.0-beta.11",
+		"v1.0.0-rc.1",
+		"v1.0.0-rc.9",
+		"v1.0.0-rc.10",
+		"v1.0.0",
+		"v1.0.1",
+		"v1.2.0",
+		"v1.10.0",
+	}
+	releases := make([]release, len(tags))
+	for i, tag := range tags {
+		m := semverTag.FindStringSubmatch(tag)
+		if m == nil {
+			t.Fatalf("%s is not a release tag", tag)
+		}
+		r := release{Tag: tag, Pre: m[4]}
+		for j := range r.Version {
+			r.Version[j], _ = strconv.Atoi(m[j+1])
+		}
+		releases[len(tags)-1-i] = r
+	}
+	sort.Slice(releases, func(i, j int) bool { return releases[i].less(releases[j]) })
+	for i, r := range releases {
+		if r.Tag != tags[i] {
+			t.Errorf("release %d = %s, want %s", i, r.Tag, tags[i])
+		}
+	}
+}
+
+func TestParseChangelog(t *testing.T) {
+	tests := []struct {
+		name      string
+		existing  string
+		wantIntro string
+		want      []changelogSection
+	}{
+		{
+			name:      "new file",
+			wantIntro: defaultChangelogIntro,
+		},
+		{
+			name:      "no releases",
+			existing:  "# Changelog\n\nNotes.\n",
+			wantIntro: "# Changelog\n\nNotes.\n",
+		},
+		{
+			name:      "releases",
+			existing:  "# Changelog\n\n## Unreleased\n\n- WIP.\n\n## v1.1.0 (2024-02-01)\n\n### Added\n\n- Note.\n\n## [v1.0.0] - 2024-01-01\n\nInitial release.\n",
+			wantIntro: "# Changelog\n\n",
+			want: []changelogSection{
+				{"Unreleased", "## Unreleased\n\n- WIP.\n\n"},
+				{"v1.1.0", "## v1.1.0 (2024-02-01)\n\n### Added\n\n- Note.\n\n"},
+				{"v1.0.0", "## [v1.0.0] - 2024-01-01\n\nInitial release.\n"},
+			},
+		},
+		{
+			name:      "empty heading",
+			existing:  "## \n",
+			wantIntro: "",
+			want:      []changelogSection{{"", "## \n"}},
+		},
+	}
+	for _, tt := range tests {
+		intro, sections := parseChangelog([]byte(tt.existing))
+		if intro != tt.wantIntro {
+			t.Errorf("%s: intro = %q, want %q", tt.name, intro, tt.wantIntro)
+		}
+		if !reflect.DeepEqual(sections, tt.want) {
+			t.Errorf("%s: sections = %q, want %q", tt.name, sections, tt.want)
+		}
+	}
+}
+
+func TestMergeChangelog(t *testing.T) {
+	order := map[string]int{"v1.0.0": 0, "v1.0.1": 1, "v1.1.0": 2, "v2.0.0": 3}
+	tests := []struct {
+		name            string
+		existing, added []string
+		want            []string
+	}{
+		{"new file", nil, []string{"v1.1.0", "v1.0.0"}, []string{"v1.1.0", "v1.0.0"}},
+		{"new release", []string{"v1.1.0", "v1.0.0"}, []string{"v2.0.0"}, []string{"v2.0.0", "v1.1.0", "v1.0.0"}},
+		{"older release", []string{"v1.1.0", "v1.0.0"}, []string{"v1.0.1"}, []string{"v1.1.0", "v1.0.1", "v1.0.0"}},
+		{"unreleased stays first", []string{"Unreleased", "v1.0.0"}, []string{"v2.0.0", "v1.1.0"}, []string{"Unreleased", "v2.0.0", "v1.1.0", "v1.0.0"}},
+		{"deleted tag kept", []string{"v1.1.0", "v0.9.0"}, []string{"v1.0.0"}, []string{"v1.1.0", "v0.9.0", "v1.0.0"}},
+	}
+	for _, tt := range tests {
+		var existing, added []changelogSection
+		for _, tag := range tt.existing {
+			existing = append(existing, changelogSection{Tag: tag})
+		}
+		for _, tag := range tt.added {
+			added = append(added, changelogSection{Tag: tag})
+		}
+		var got []string
+		for _, s := range mergeChangelog(existing, added, order) {
+			got = append(got, s.Tag)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: mergeChangelog = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
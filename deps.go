@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Files recording the dependencies installed by `terraform init`.
+const (
+	lockFile    = ".terraform.lock.hcl"
+	modulesFile = ".terraform/modules/modules.json"
+)
+
+// providerLock is a provider pinned in the dependency lock file.
+type providerLock struct {
+	Source      string // Full source address, e.g. "registry.terraform.io/hashicorp/aws".
+	Version     string
+	Constraints string
+	Hashes      int
+}
+
+// installedModule is a module installed by `terraform init`.
+type installedModule struct {
+	Key     string // Dotted module call path, e.g. "vpc.subnets".
+	Source  string
+	Version string
+}
+
+// dependencies are the provider and module versions a module was last
+// initialized with.
+type dependencies struct {
+	Providers []providerLock
+	Modules   []installedModule
+}
+
+// readDependencies reads the lock file and the installed modules manifest
+// of the module in dir. Missing files are skipped.
+func readDependencies(dir string) (*dependencies, error) {
+	deps := &dependencies{}
+
+	f, err := parseHCLFile(filepath.Join(dir, lockFile))
+	switch {
+	case os.IsNotExist(errors.Cause(err)):
+	case err != nil:
+		return nil, errors.Wrap(err, lockFile)
+	default:
+		for _, block := range nestedBlocks(f.Body, "provider", "source") {
+			attrs := attributes(block.Body, "version", "constraints", "hashes")
+			hashes, _ := attrValue(attrs, "hashes").([]interface{})
+			deps.Providers = append(deps.Providers, providerLock{
+				Source:      block.Labels[0],
+				Version:     attrString(attrs, "version"),
+				Constraints: attrString(attrs, "constraints"),
+				Hashes:      len(hashes),
+			})
+		}
+	}
+
+	raw, err := ioutil.ReadFile(filepath.Join(dir, modulesFile))
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return nil, errors.Wrapf(err, "read %s", modulesFile)
+	default:
+		var manifest struct {
+			Modules []installedModule `json:"Modules"`
+		}
+		if err := json.Unmarshal(raw, &manifest); err != nil {
+			return nil, errors.Wrapf(err, "parse %s", modulesFile)
+		}
+		for _, mod := range manifest.Modules {
+			// The root module has an empty key.
+			if mod.Key != "" {
+				deps.Modules = append(deps.Modules, mod)
+			}
+		}
+		sort.Slice(deps.Modules, func(i, j int) bool { return deps.Modules[i].Key < deps.Modules[j].Key })
+	}
+	return deps, nil
+}
+
+// constraint returns the version constraint of a locked provider from the
+// module's requirements, falling back to the constraints recorded in the
+// lock file.
+func constraint(lock providerLock, reqs []ProviderRequirement) string {
+	for _, p := range reqs {
+		if p.Version == "" {
+			continue
+		}
+		source := p.Source
+		if source == "" {
+			source = "hashicorp/" + p.Name
+		}
+		if strings.TrimPrefix(lock.Source, "registry.terraform.io/") == source || lock.Source == source {
+			return p.Version
+		}
+	}
+	return lock.Constraints
+}
+
+// dependencySection returns the section listing the locked provider
+// versions and installed modules, or nil if there are none.
+func dependencySection(deps *dependencies, reqs []ProviderRequirement) *Section {
+	if len(deps.Providers) == 0 && len(deps.Modules) == 0 {
+		return nil
+	}
+	s := &Section{
+		Title:  "Dependencies",
+		Blocks: []Block{Paragraph{{Value: "The versions the module was last initialized with."}}},
+	}
+	if len(deps.Providers) > 0 {
+		table := &Table{Columns: []Column{
+			{Title: "Provider"},
+			{Title: "Constraint", Align: AlignCenter},
+			{Title: "Locked version", Align: AlignCenter},
+			{Title: "Hashes", Align: AlignCenter},
+		}}
+		for _, p := range deps.Providers {
+			table.Rows = append(table.Rows, Row{Cells: []Cell{
+				code(p.Source),
+				code(constraint(p, reqs)),
+				code(p.Version),
+				plain(strconv.Itoa(p.Hashes)),
+			}})
+		}
+		s.Blocks = append(s.Blocks, &Section{Title: "Providers", Blocks: []Block{table}})
+	}
+	if len(deps.Modules) > 0 {
+		table := &Table{Columns: []Column{
+			{Title: "Name"},
+			{Title: "Source"},
+			{Title: "Version", Align: AlignCenter},
+		}}
+		for _, mod := range deps.Modules {
+			table.Rows = append(table.Rows, Row{Cells: []Cell{
+				plain("module." + strings.Replace(mod.Key, ".", ".module.", -1)),
+				code(mod.Source),
+				code(mod.Version),
+			}})
+		}
+		s.Blocks = append(s.Blocks, &Section{Title: "Installed modules", Blocks: []Block{table}})
+	}
+	return s
+}
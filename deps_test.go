@@ -0,0 +1,119 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadDependencies(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		".terraform.lock.hcl": `
+# This file is maintained automatically by "terraform init".
+
+provider "registry.terraform.io/hashicorp/aws" {
+  version     = "5.31.0"
+  constraints = "~> 5.0"
+  hashes = [
+    "h1:aaaa",
+    "zh:bbbb",
+    "zh:cccc",
+  ]
+}
+
+provider "registry.terraform.io/hashicorp/random" {
+  version = "3.6.0"
+}
+`,
+		".terraform/modules/modules.json": `{"Modules": [
+  {"Key": "vpc.subnets", "Source": "./subnets", "Dir": ".terraform/modules/vpc/subnets"},
+  {"Key": "", "Source": "", "Dir": "."},
+  {"Key": "vpc", "Source": "registry.terraform.io/terraform-aws-modules/vpc/aws", "Version": "5.4.0", "Dir": ".terraform/modules/vpc"}
+]}`,
+	})
+	deps, err := readDependencies(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &dependencies{
+		Providers: []providerLock{
+			{Source: "registry.terraform.io/hashicorp/aws", Version: "5.31.0", Constraints: "~> 5.0", Hashes: 3},
+			{Source: "registry.terraform.io/hashicorp/random", Version: "3.6.0"},
+		},
+		Modules: []installedModule{
+			{Key: "vpc", Source: "registry.terraform.io/terraform-aws-modules/vpc/aws", Version: "5.4.0"},
+			{Key: "vpc.subnets", Source: "./subnets"},
+		},
+	}
+	if !reflect.DeepEqual(deps, want) {
+		t.Errorf("readDependencies = %+v, want %+v", deps, want)
+	}
+}
+
+func TestReadDependenciesMissing(t *testing.T) {
+	deps, err := readDependencies(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(deps, &dependencies{}) {
+		t.Errorf("readDependencies = %+v, want none", deps)
+	}
+	if s := dependencySection(deps, nil); s != nil {
+		t.Errorf("dependencySection = %+v, want nil", s)
+	}
+
+	dir := writeModule(t, map[string]string{".terraform/modules/modules.json": "{"})
+	if _, err := readDependencies(dir); err == nil {
+		t.Error("readDependencies with a malformed modules.json succeeded")
+	}
+}
+
+func TestConstraint(t *testing.T) {
+	reqs := []ProviderRequirement{
+		{Name: "aws", Version: ">= 5.0"},
+		{Name: "random"},
+		{Name: "datadog", Source: "DataDog/datadog", Version: "~> 3.30"},
+	}
+	tests := []struct {
+		lock providerLock
+		want string
+	}{
+		{providerLock{Source: "registry.terraform.io/hashicorp/aws", Constraints: "~> 5.0"}, ">= 5.0"},
+		{providerLock{Source: "registry.terraform.io/DataDog/datadog"}, "~> 3.30"},
+		{providerLock{Source: "registry.terraform.io/hashicorp/random", Constraints: "3.6.0"}, "3.6.0"},
+		{providerLock{Source: "registry.terraform.io/hashicorp/null"}, ""},
+	}
+	for _, tt := range tests {
+		if got := constraint(tt.lock, reqs); got != tt.want {
+			t.Errorf("constraint(%s) = %q, want %q", tt.lock.Source, got, tt.want)
+		}
+	}
+}
+
+func TestDependencySection(t *testing.T) {
+	deps := &dependencies{
+		Providers: []providerLock{{Source: "registry.terraform.io/hashicorp/aws", Version: "5.31.0", Constraints: "~> 5.0", Hashes: 3}},
+		Modules:   []installedModule{{Key: "vpc.subnets", Source: "./subnets"}},
+	}
+	s := dependencySection(deps, nil)
+	var rows [][]string
+	for _, blk := range s.Blocks[1:] {
+		for _, r := range blk.(*Section).Blocks[0].(*Table).Rows {
+			var cells []string
+			for _, c := range r.Cells {
+				if len(c) == 0 {
+					cells = append(cells, "")
+					continue
+				}
+				cells = append(cells, c[0].Value)
+			}
+			rows = append(rows, cells)
+		}
+	}
+	want := [][]string{
+		{"registry.terraform.io/hashicorp/aws", "~> 5.0", "5.31.0", "3"},
+		{"module.vpc.module.subnets", "./subnets", ""},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q, want %q", rows, want)
+	}
+}
@@ -0,0 +1,425 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Bump is a semantic version increment.
+type Bump int
+
+// Version increments, in increasing order of severity.
+const (
+	BumpNone Bump = iota
+	BumpPatch
+	BumpMinor
+	BumpMajor
+)
+
+func (b Bump) String() string {
+	return [...]string{"none", "patch", "minor", "major"}[b]
+}
+
+// ChangeKind is what happened to an element of the interface.
+type ChangeKind int
+
+// Kinds of change.
+const (
+	Changed ChangeKind = iota
+	Added
+	Removed
+)
+
+// Change is a difference between two versions of a module interface.
+type Change struct {
+	Bump        Bump
+	Kind        ChangeKind
+	Description string
+}
+
+// assignable reports whether every value of type from is accepted by type
+// to.
+func assignable(from, to *TypeExpr) bool {
+	if to.Kind == "any" {
+		return true
+	}
+	switch from.Kind {
+	case "any":
+		return false
+	case "string", "number", "bool":
+		return from.Kind == to.Kind || to.Kind == "string"
+	case "list", "set":
+		return (to.Kind == "list" || to.Kind == "set") && assignable(from.Elem, to.Elem)
+	case "tuple":
+		switch to.Kind {
+		case "list", "set":
+			for _, e := range from.Elems {
+				if !assignable(e, to.Elem) {
+					return false
+				}
+			}
+			return true
+		case "tuple":
+			if len(from.Elems) != len(to.Elems) {
+				return false
+			}
+			for i := range from.Elems {
+				if !assignable(from.Elems[i], to.Elems[i]) {
+					return false
+				}
+			}
+			return true
+		}
+		return false
+	case "map":
+		return to.Kind == "map" && assignable(from.Elem, to.Elem)
+	case "object":
+		if to.Kind != "object" {
+			return false
+		}
+		attrs := make(map[string]TypeAttr, len(from.Attrs))
+		for _, a := range from.Attrs {
+			attrs[a.Name] = a
+		}
+		for _, b := range to.Attrs {
+			a, ok := attrs[b.Name]
+			if !ok {
+				if !b.Optional {
+					return false
+				}
+				continue
+			}
+			if a.Optional && !b.Optional || !assignable(a.Type, b.Type) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// typeChange classifies a change of type constraint.
+func typeChange(kind, name, from, to string) *Change {
+	if from == to {
+		return nil
+	}
+	desc := fmt.Sprintf("%s `%s` type changed from `%s` to `%s`", kind, name, typeLabel(from), typeLabel(to))
+	oldType, err1 := parseType(from)
+	newType, err2 := parseType(to)
+	if err1 != nil || err2 != nil {
+		return &Change{BumpMajor, Changed, desc}
+	}
+	switch oldFits, newFits := assignable(oldType, newType), assignable(newType, oldType); {
+	case oldFits && newFits:
+		return &Change{BumpPatch, Changed, desc}
+	case oldFits:
+		return &Change{BumpMinor, Changed, desc + " (widened)"}
+	}
+	return &Change{BumpMajor, Changed, desc + " (narrowed)"}
+}
+
+func typeLabel(t string) string {
+	return orDefault(t, "any")
+}
+
+// orDefault returns s, or def if s is empty.
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// renamed pairs removed and added entries that look like the same one
+// under a new name: same type and the same non-empty description.
+func renamed(removed, added []HCLVar) map[string]string {
+	pairs := map[string]string{}
+	used := map[string]bool{}
+	for _, r := range removed {
+		if r.Description == "" {
+			continue
+		}
+		for _, a := range added {
+			if !used[a.Name] && a.Description == r.Description && a.VarType == r.VarType {
+				pairs[r.Name], used[a.Name] = a.Name, true
+				break
+			}
+		}
+	}
+	return pairs
+}
+
+// diffVars lists the entries only in old, only in new, and in both.
+func diffVars(old, new []HCLVar) (removed, added []HCLVar, common [][2]HCLVar) {
+	newByName := make(map[string]HCLVar, len(new))
+	for _, v := range new {
+		newByName[v.Name] = v
+	}
+	oldNames := make(map[string]bool, len(old))
+	for _, o := range old {
+		oldNames[o.Name] = true
+		if n, ok := newByName[o.Name]; ok {
+			common = append(common, [2]HCLVar{o, n})
+		} else {
+			removed = append(removed, o)
+		}
+	}
+	for _, n := range new {
+		if !oldNames[n.Name] {
+			added = append(added, n)
+		}
+	}
+	return removed, added, common
+}
+
+// diffInputs classifies the changes between two versions of a module's
+// inputs.
+func diffInputs(old, new []HCLVar) []Change {
+	var changes []Change
+	removed, added, common := diffVars(old, new)
+	renames := renamed(removed, added)
+	renamedTo := map[string]bool{}
+	for _, r := range removed {
+		if to, ok := renames[r.Name]; ok {
+			renamedTo[to] = true
+			changes = append(changes, Change{BumpMajor, Changed, fmt.Sprintf("Input `%s` renamed to `%s`", r.Name, to)})
+			continue
+		}
+		changes = append(changes, Change{BumpMajor, Removed, fmt.Sprintf("Input `%s` removed", r.Name)})
+	}
+	for _, a := range added {
+		switch {
+		case renamedTo[a.Name]:
+		case a.Required:
+			changes = append(changes, Change{BumpMajor, Added, fmt.Sprintf("New required input `%s`", a.Name)})
+		default:
+			changes = append(changes, Change{BumpMinor, Added, fmt.Sprintf("New optional input `%s`", a.Name)})
+		}
+	}
+
+	for _, pair := range common {
+		o, n := pair[0], pair[1]
+		if c := typeChange("Input", n.Name, o.VarType, n.VarType); c != nil {
+			changes = append(changes, *c)
+		}
+		switch {
+		case !o.Required && n.Required:
+			changes = append(changes, Change{BumpMajor, Changed, fmt.Sprintf("Input `%s` is now required", n.Name)})
+		case o.Required && !n.Required:
+			changes = append(changes, Change{BumpMinor, Changed, fmt.Sprintf("Input `%s` is now optional, defaulting to `%s`", n.Name, displayDefault(n))})
+		case !o.Required && !reflect.DeepEqual(o.Default, n.Default):
+			changes = append(changes, Change{BumpPatch, Changed, fmt.Sprintf("Input `%s` default changed from `%s` to `%s`", n.Name, displayDefault(o), displayDefault(n))})
+		}
+		switch {
+		case o.Nullable && !n.Nullable:
+			changes = append(changes, Change{BumpMajor, Changed, fmt.Sprintf("Input `%s` no longer accepts null", n.Name)})
+		case !o.Nullable && n.Nullable:
+			changes = append(changes, Change{BumpMinor, Changed, fmt.Sprintf("Input `%s` now accepts null", n.Name)})
+		}
+		if o.Sensitive != n.Sensitive {
+			changes = append(changes, Change{BumpPatch, Changed, fmt.Sprintf("Input `%s` sensitivity changed to %t", n.Name, n.Sensitive)})
+		}
+		if o.Description != n.Description {
+			changes = append(changes, Change{BumpPatch, Changed, fmt.Sprintf("Input `%s` description changed", n.Name)})
+		}
+	}
+	return changes
+}
+
+// diffOutputs classifies the changes between two versions of a module's
+// outputs.
+func diffOutputs(old, new []HCLVar) []Change {
+	var changes []Change
+	removed, added, common := diffVars(old, new)
+	renames := renamed(removed, added)
+	renamedTo := map[string]bool{}
+	for _, r := range removed {
+		if to, ok := renames[r.Name]; ok {
+			renamedTo[to] = true
+			changes = append(changes, Change{BumpMajor, Changed, fmt.Sprintf("Output `%s` renamed to `%s`", r.Name, to)})
+			continue
+		}
+		changes = append(changes, Change{BumpMajor, Removed, fmt.Sprintf("Output `%s` removed", r.Name)})
+	}
+	for _, a := range added {
+		if !renamedTo[a.Name] {
+			changes = append(changes, Change{BumpMinor, Added, fmt.Sprintf("New output `%s`", a.Name)})
+		}
+	}
+	for _, pair := range common {
+		o, n := pair[0], pair[1]
+		switch {
+		case !o.Sensitive && n.Sensitive:
+			// Callers using the value in non-sensitive contexts will fail.
+			changes = append(changes, Change{BumpMajor, Changed, fmt.Sprintf("Output `%s` is now sensitive", n.Name)})
+		case o.Sensitive && !n.Sensitive:
+			changes = append(changes, Change{BumpPatch, Changed, fmt.Sprintf("Output `%s` is no longer sensitive", n.Name)})
+		}
+		if o.Description != n.Description {
+			changes = append(changes, Change{BumpPatch, Changed, fmt.Sprintf("Output `%s` description changed", n.Name)})
+		}
+	}
+	return changes
+}
+
+// versionNumber matches a number of a version constraint.
+var versionNumber = regexp.MustCompile(`\d+`)
+
+// majorOf returns the first version number in a constraint such as
+// "~> 3.2", or -1 if there is none.
+func majorOf(constraint string) int {
+	m := versionNumber.FindString(constraint)
+	if m == "" {
+		return -1
+	}
+	n, _ := strconv.Atoi(m)
+	return n
+}
+
+// diffProviders classifies the changes between two versions of a module's
+// Terraform and provider requirements. A constraint moving to a new major
+// version is assumed to be breaking.
+func diffProviders(old, new *Module) []Change {
+	var changes []Change
+	versionChange := func(what, from, to string) {
+		if from == to {
+			return
+		}
+		bump := BumpMinor
+		if from != "" && majorOf(to) > majorOf(from) {
+			bump = BumpMajor
+		}
+		changes = append(changes, Change{bump, Changed, fmt.Sprintf("%s version constraint changed from `%s` to `%s`", what, orDefault(from, "none"), orDefault(to, "none"))})
+	}
+	versionChange("Terraform", old.RequiredVersion, new.RequiredVersion)
+
+	oldByName := make(map[string]ProviderRequirement, len(old.Providers))
+	for _, p := range old.Providers {
+		oldByName[p.Name] = p
+	}
+	newNames := map[string]bool{}
+	for _, p := range new.Providers {
+		newNames[p.Name] = true
+		o, ok := oldByName[p.Name]
+		if !ok {
+			changes = append(changes, Change{BumpMinor, Added, fmt.Sprintf("New provider requirement `%s`", p.Name)})
+			continue
+		}
+		if o.Source != p.Source && o.Source != "" {
+			changes = append(changes, Change{BumpMajor, Changed, fmt.Sprintf("Provider `%s` source changed from `%s` to `%s`", p.Name, o.Source, orDefault(p.Source, "none"))})
+		}
+		versionChange(fmt.Sprintf("Provider `%s`", p.Name), o.Version, p.Version)
+	}
+	for _, p := range old.Providers {
+		if !newNames[p.Name] {
+			changes = append(changes, Change{BumpPatch, Removed, fmt.Sprintf("Provider requirement `%s` removed", p.Name)})
+		}
+	}
+	return changes
+}
+
+// diffModules classifies the interface changes between two module versions.
+func diffModules(old, new *Module) []Change {
+	changes := append(diffInputs(old.Inputs, new.Inputs), diffOutputs(old.Outputs, new.Outputs)...)
+	return append(changes, diffProviders(old, new)...)
+}
+
+// suggestedBump returns the most severe bump of the changes.
+func suggestedBump(changes []Change) Bump {
+	bump := BumpNone
+	for _, c := range changes {
+		if c.Bump > bump {
+			bump = c.Bump
+		}
+	}
+	return bump
+}
+
+// changeSections groups changes by severity into document sections.
+func changeSections(changes []Change) []Block {
+	titles := map[Bump]string{
+		BumpMajor: "Breaking changes",
+		BumpMinor: "Features",
+		BumpPatch: "Other changes",
+	}
+	var blocks []Block
+	for _, bump := range []Bump{BumpMajor, BumpMinor, BumpPatch} {
+		var list List
+		for _, c := range changes {
+			if c.Bump == bump {
+				list = append(list, codeSpans(c.Description))
+			}
+		}
+		if len(list) > 0 {
+			blocks = append(blocks, &Section{Title: titles[bump], Blocks: []Block{list}})
+		}
+	}
+	return blocks
+}
+
+// diffDocument summarises interface changes as a document.
+func diffDocument(oldName, newName string, changes []Change) *Document {
+	summary := Paragraph{
+		{Value: "Interface changes from "},
+		{Value: oldName, Code: true},
+		{Value: " to "},
+		{Value: newName, Code: true},
+		{Value: fmt.Sprintf(". Suggested version bump: %s.", suggestedBump(changes))},
+	}
+	if len(changes) == 0 {
+		summary = append(summary, Text{Value: " No interface changes."})
+	}
+	return &Document{
+		Sections: []*Section{{
+			Title:  "Interface changes",
+			Blocks: append([]Block{summary}, changeSections(changes)...),
+		}},
+	}
+}
+
+// diffFormats are the -format values of the diff command: the text
+// formats, as a diff has no diagram to render as dot.
+var diffFormats = []string{"asciidoc", "html", "markdown", "rst"}
+
+// runDiff implements the diff command.
+func runDiff(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	var (
+		dir    = fs.String("path", ".", "module directory, used when comparing git revisions")
+		format = fs.String("format", "markdown", "output format: "+strings.Join(diffFormats, ", "))
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: tfreadme diff [flags] <old> <new>\n\nEach of old and new is a module directory or a git revision.\n\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if !containsString(diffFormats, *format) {
+		log.Fatalf("Unknown format %q, expected one of: %s.", *format, strings.Join(diffFormats, ", "))
+	}
+	renderer := renderers[*format]
+
+	var modules [2]*Module
+	for i, arg := range fs.Args() {
+		m, err := loadModule(sourceFor(arg, *dir))
+		if err != nil {
+			log.Fatalf("Error loading module %q: %s.", arg, err)
+		}
+		modules[i] = m
+	}
+
+	changes := diffModules(modules[0], modules[1])
+	doc := diffDocument(fs.Arg(0), fs.Arg(1), changes)
+	if err := renderer.Render(os.Stdout, doc); err != nil {
+		log.Fatalf("Error rendering %s: %s.", *format, err)
+	}
+}
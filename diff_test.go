@@ -0,0 +1,130 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAssignable(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{"string", "string", true},
+		{"number", "string", true},
+		{"bool", "string", true},
+		{"string", "number", false},
+		{"string", "any", true},
+		{"any", "string", false},
+		{"list(string)", "set(string)", true},
+		{"list(number)", "list(string)", true},
+		{"list(string)", "list(number)", false},
+		{"list(string)", "map(string)", false},
+		{"tuple([string, number])", "list(string)", true},
+		{"tuple([string, bool])", "list(number)", false},
+		{"tuple([string, number])", "tuple([string, string])", true},
+		{"tuple([string])", "tuple([string, string])", false},
+		{"map(number)", "map(string)", true},
+		{"map(string)", "object({ a = string })", false},
+		{"object({ a = string, b = number })", "object({ a = string })", true},
+		{"object({ a = string })", "object({ a = string, b = number })", false},
+		{"object({ a = string })", "object({ a = string, b = optional(number) })", true},
+		{"object({ a = optional(string) })", "object({ a = string })", false},
+		{"object({ a = number })", "object({ a = string })", true},
+		{"object({ a = string })", "object({ a = number })", false},
+	}
+	for _, tt := range tests {
+		from, err := parseType(tt.from)
+		if err != nil {
+			t.Fatalf("parseType(%q): %s", tt.from, err)
+		}
+		to, err := parseType(tt.to)
+		if err != nil {
+			t.Fatalf("parseType(%q): %s", tt.to, err)
+		}
+		if got := assignable(from, to); got != tt.want {
+			t.Errorf("assignable(%s, %s) = %t, want %t", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestDiffInputs(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new []HCLVar
+		want     []Change
+	}{
+		{
+			name: "unchanged",
+			old:  []HCLVar{{Name: "a", VarType: "string", Required: true}},
+			new:  []HCLVar{{Name: "a", VarType: "string", Required: true}},
+		},
+		{
+			name: "removed",
+			old:  []HCLVar{{Name: "a", VarType: "string"}},
+			want: []Change{{BumpMajor, Removed, "Input `a` removed"}},
+		},
+		{
+			name: "added",
+			new: []HCLVar{
+				{Name: "req", Required: true},
+				{Name: "opt", Default: "x", DefaultVal: `"x"`},
+			},
+			want: []Change{
+				{BumpMajor, Added, "New required input `req`"},
+				{BumpMinor, Added, "New optional input `opt`"},
+			},
+		},
+		{
+			name: "renamed",
+			old:  []HCLVar{{Name: "subnet", VarType: "string", Description: "Subnet."}},
+			new:  []HCLVar{{Name: "subnet_id", VarType: "string", Description: "Subnet."}},
+			want: []Change{{BumpMajor, Changed, "Input `subnet` renamed to `subnet_id`"}},
+		},
+		{
+			name: "type widened",
+			old:  []HCLVar{{Name: "a", VarType: "number", Required: true}},
+			new:  []HCLVar{{Name: "a", VarType: "string", Required: true}},
+			want: []Change{{BumpMinor, Changed, "Input `a` type changed from `number` to `string` (widened)"}},
+		},
+		{
+			name: "type narrowed",
+			old:  []HCLVar{{Name: "a", Required: true}},
+			new:  []HCLVar{{Name: "a", VarType: "list(string)", Required: true}},
+			want: []Change{{BumpMajor, Changed, "Input `a` type changed from `any` to `list(string)` (narrowed)"}},
+		},
+		{
+			name: "now required",
+			old:  []HCLVar{{Name: "a", Default: "x", DefaultVal: `"x"`}},
+			new:  []HCLVar{{Name: "a", Required: true}},
+			want: []Change{{BumpMajor, Changed, "Input `a` is now required"}},
+		},
+		{
+			name: "now optional",
+			old:  []HCLVar{{Name: "a", Required: true}},
+			new:  []HCLVar{{Name: "a", Default: int64(3), DefaultVal: "3"}},
+			want: []Change{{BumpMinor, Changed, "Input `a` is now optional, defaulting to `3`"}},
+		},
+		{
+			name: "sensitive default changed",
+			old:  []HCLVar{{Name: "a", Default: "x", DefaultVal: `"x"`, Sensitive: true}},
+			new:  []HCLVar{{Name: "a", Default: "y", DefaultVal: `"y"`, Sensitive: true}},
+			want: []Change{{BumpPatch, Changed, "Input `a` default changed from `<sensitive>` to `<sensitive>`"}},
+		},
+		{
+			name: "nullability and description",
+			old:  []HCLVar{{Name: "a", Required: true, Nullable: true, Description: "Old."}},
+			new:  []HCLVar{{Name: "a", Required: true, Description: "New."}},
+			want: []Change{
+				{BumpMajor, Changed, "Input `a` no longer accepts null"},
+				{BumpPatch, Changed, "Input `a` description changed"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		got := diffInputs(tt.old, tt.new)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: diffInputs = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,518 @@
+package main
+
+import "strings"
+
+// Document is the markup-independent model of a generated README.
+type Document struct {
+	Title    string
+	Sections []*Section
+}
+
+// Block is an element of a section body.
+type Block interface {
+	block()
+}
+
+func (*Section) block()  {}
+func (Paragraph) block() {}
+func (List) block()      {}
+func (*Table) block()    {}
+func (CodeBlock) block() {}
+func (Contents) block()  {}
+
+// Section is a titled part of the document. Sections nest by appearing in
+// the blocks of their parent. Anchor is the fragment linking to the
+// section; when empty, renderers derive it from the title.
+type Section struct {
+	Title  string
+	Anchor string
+	Blocks []Block
+}
+
+// Contents is a table of contents.
+type Contents []ContentsEntry
+
+// ContentsEntry links to a section and lists its subsections.
+type ContentsEntry struct {
+	Title   string
+	Anchor  string
+	Entries Contents
+}
+
+// Text is an inline run of text. Strong takes precedence over Code;
+// Strike applies to either, and Link, if set, makes the run a hyperlink.
+type Text struct {
+	Value  string
+	Code   bool
+	Strong bool
+	Strike bool
+	Link   string
+}
+
+// Paragraph is a sequence of inline runs.
+type Paragraph []Text
+
+// List is a bullet list.
+type List []Paragraph
+
+// CodeBlock is a preformatted block of source code.
+type CodeBlock struct {
+	Lang string
+	Code string
+}
+
+// Alignment is the horizontal alignment of a table column.
+type Alignment int
+
+// Column alignments.
+const (
+	AlignLeft Alignment = iota
+	AlignCenter
+)
+
+// Column is a table column header.
+type Column struct {
+	Title string
+	Align Alignment
+}
+
+// Cell is the content of a single table cell.
+type Cell []Text
+
+// Row is a table row. Formats that support it make the row linkable
+// through its anchor.
+type Row struct {
+	Anchor string
+	Cells  []Cell
+}
+
+// Table is a table with a header row.
+type Table struct {
+	Columns []Column
+	Rows    []Row
+}
+
+// plain returns a cell holding s as plain text, or an empty cell if s is empty.
+func plain(s string) Cell {
+	if s == "" {
+		return nil
+	}
+	return Cell{{Value: s}}
+}
+
+// code returns a cell holding s as code, or an empty cell if s is empty.
+func code(s string) Cell {
+	if s == "" {
+		return nil
+	}
+	return Cell{{Value: s, Code: true}}
+}
+
+// codeSpans parses a string with `backtick` code spans into a paragraph.
+func codeSpans(s string) Paragraph {
+	var p Paragraph
+	for i, part := range strings.Split(s, "`") {
+		if part != "" {
+			p = append(p, Text{Value: part, Code: i%2 == 1})
+		}
+	}
+	return p
+}
+
+// yesNo returns a cell reading "yes" or "no".
+func yesNo(b bool) Cell {
+	if b {
+		return plain("yes")
+	}
+	return plain("no")
+}
+
+// usedBy returns a cell listing the usages of an input, one per line,
+// or flagging it as unused.
+func usedBy(usages []Usage) Cell {
+	if len(usages) == 0 {
+		return Cell{{Value: "unused", Strong: true}}
+	}
+	var c Cell
+	for i, u := range usages {
+		if i > 0 {
+			c = append(c, Text{Value: "\n"})
+		}
+		c = append(c, Text{Value: u.Address, Code: true})
+		if len(u.Attrs) > 0 {
+			c = append(c, Text{Value: " (" + strings.Join(u.Attrs, ", ") + ")"})
+		}
+	}
+	return c
+}
+
+// nameCell returns a cell holding the name of a variable or output, linked
+// to its declaration if link is set, and struck through and naming its
+// replacement if it is deprecated.
+func nameCell(v HCLVar, link sourceLinker) Cell {
+	name := Text{Value: v.Name, Strike: v.Deprecated}
+	if link != nil {
+		name.Link = link(v.Pos)
+	}
+	if !v.Deprecated {
+		return Cell{name}
+	}
+	c := Cell{name, {Value: " "}, {Value: "deprecated", Strong: true}}
+	if v.Replacement != "" {
+		c = append(c, Text{Value: ", use "}, Text{Value: v.Replacement, Code: true})
+	}
+	return c
+}
+
+// codeLines returns a cell holding values as code, one per line.
+func codeLines(values []string) Cell {
+	var c Cell
+	for i, v := range values {
+		if i > 0 {
+			c = append(c, Text{Value: "\n"})
+		}
+		c = append(c, Text{Value: v, Code: true})
+	}
+	return c
+}
+
+// anyVar reports whether f holds for any of vars.
+func anyVar(vars []HCLVar, f func(HCLVar) bool) bool {
+	for _, v := range vars {
+		if f(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// upgradeNotes returns the section describing the state changes of the
+// migrations of a module, or nil if it has none.
+func upgradeNotes(migrations []Migration) *Section {
+	if len(migrations) == 0 {
+		return nil
+	}
+	var moved, removed, imported List
+	for _, mig := range migrations {
+		switch mig.Kind {
+		case migrationMoved:
+			moved = append(moved, Paragraph{{Value: mig.From, Code: true}, {Value: " is now "}, {Value: mig.To, Code: true}})
+		case migrationRemoved:
+			effect := " is destroyed"
+			if !mig.Destroy {
+				effect = " is removed from the state without being destroyed"
+			}
+			removed = append(removed, Paragraph{{Value: mig.From, Code: true}, {Value: effect}})
+		case migrationImport:
+			p := Paragraph{{Value: mig.To, Code: true}, {Value: " is imported"}}
+			if mig.ID != "" {
+				p = append(p, Text{Value: " from "}, Text{Value: mig.ID, Code: true})
+			}
+			imported = append(imported, p)
+		}
+	}
+
+	s := &Section{
+		Title:  "Upgrade Notes",
+		Blocks: []Block{Paragraph{{Value: "Applying this version of the module changes the state of existing deployments as follows."}}},
+	}
+	for _, sub := range []struct {
+		title string
+		items List
+	}{
+		{"Moved", moved},
+		{"Removed", removed},
+		{"Imported", imported},
+	} {
+		if len(sub.items) > 0 {
+			s.Blocks = append(s.Blocks, &Section{Title: sub.title, Blocks: []Block{sub.items}})
+		}
+	}
+	return s
+}
+
+// callRows returns the table rows of module calls and, when the loader
+// resolved them, of the calls of the called modules.
+func callRows(prefix string, calls []ModuleCall) []Row {
+	var rows []Row
+	for _, call := range calls {
+		address := prefix + "module." + call.Name
+		rows = append(rows, Row{
+			Anchor: address,
+			Cells:  []Cell{plain(address), code(call.Source), code(call.Version)},
+		})
+		if call.Module != nil {
+			rows = append(rows, callRows(address+".", call.Module.Calls)...)
+		}
+	}
+	return rows
+}
+
+// resourceSection returns the section listing resources with the
+// descriptions of their types.
+func resourceSection(resources []Resource) *Section {
+	table := &Table{Columns: []Column{{Title: "Name"}, {Title: "Description"}}}
+	for _, r := range resources {
+		table.Rows = append(table.Rows, Row{
+			Anchor: "resource-" + r.Address(),
+			Cells:  []Cell{plain(r.Address()), Cell(codeSpans(r.Description))},
+		})
+	}
+	return &Section{Title: "Resources", Blocks: []Block{table}}
+}
+
+// guarded is an object of a module and the conditions guarding it.
+type guarded struct {
+	Address string
+	Rows    []Row
+}
+
+// conditionRows returns the table rows of conditions of the given kind.
+func conditionRows(kind string, conds []Validation) []Row {
+	rows := make([]Row, 0, len(conds))
+	for _, c := range conds {
+		rows = append(rows, Row{Cells: []Cell{
+			plain(kind),
+			code(unwrapExpr(c.Condition)),
+			Cell(codeSpans(c.ErrorMessage)),
+		}})
+	}
+	return rows
+}
+
+// guarantees returns the section listing the check blocks and the
+// preconditions and postconditions of a module, grouped by the object they
+// guard, or nil if it has none.
+func guarantees(m *Module) *Section {
+	var objects []guarded
+	for _, r := range m.Resources {
+		rows := append(conditionRows("Precondition", r.Preconditions), conditionRows("Postcondition", r.Postconditions)...)
+		objects = append(objects, guarded{r.Address(), rows})
+	}
+	for _, o := range m.Outputs {
+		objects = append(objects, guarded{"output." + o.Name, conditionRows("Precondition", o.Preconditions)})
+	}
+	for _, c := range m.Checks {
+		rows := append(conditionRows("Precondition", c.Preconditions), conditionRows("Postcondition", c.Postconditions)...)
+		objects = append(objects, guarded{"check." + c.Name, append(rows, conditionRows("Assertion", c.Asserts)...)})
+	}
+
+	s := &Section{
+		Title: "Guarantees and Assumptions",
+		Blocks: []Block{Paragraph{
+			{Value: "Preconditions are assumptions checked before an object is planned, postconditions are guarantees checked after it is applied, and the assertions of check blocks are verified on every run without blocking it."},
+		}},
+	}
+	for _, obj := range objects {
+		if len(obj.Rows) == 0 {
+			continue
+		}
+		s.Blocks = append(s.Blocks, &Section{Title: obj.Address, Blocks: []Block{&Table{
+			Columns: []Column{{Title: "Kind"}, {Title: "Condition"}, {Title: "Error message"}},
+			Rows:    obj.Rows,
+		}}})
+	}
+	if len(s.Blocks) == 1 {
+		return nil
+	}
+	return s
+}
+
+// tableOfContents returns the table of contents of sections whose anchors
+// are set.
+func tableOfContents(sections []*Section) Contents {
+	var c Contents
+	for _, s := range sections {
+		var subs []*Section
+		for _, blk := range s.Blocks {
+			if sub, ok := blk.(*Section); ok {
+				subs = append(subs, sub)
+			}
+		}
+		c = append(c, ContentsEntry{Title: s.Title, Anchor: s.Anchor, Entries: tableOfContents(subs)})
+	}
+	return c
+}
+
+// docOptions selects the optional parts of the README.
+type docOptions struct {
+	// Diagram adds an Architecture section with the module's reference
+	// graph.
+	Diagram bool
+	// UsedBy adds a column listing the objects referencing each input.
+	UsedBy bool
+	// Resources adds a Resources section listing the resources and data
+	// sources of the module.
+	Resources bool
+	// State adds the Current Outputs and Deployed Resources sections of a
+	// deployment of the module.
+	State *deployment
+	// Dependencies adds a Dependencies section with the locked provider
+	// versions and the installed modules.
+	Dependencies *dependencies
+	// Environments adds a table of the value of each input in each
+	// environment.
+	Environments []environment
+	// SourceLinks, if set, links the names of inputs and outputs to their
+	// declarations.
+	SourceLinks sourceLinker
+	// GroupBy, if set, splits the Input table into groups of inputs: by
+	// file, annotation or name prefix.
+	GroupBy string
+	// Layout is the layout of the inputs: a table, separate tables of
+	// required and optional inputs, a section per input or a list.
+	Layout string
+	// Contents adds a table of contents at the top.
+	Contents bool
+}
+
+// newDocument builds the README document for a module.
+func newDocument(title string, m *Module, opts docOptions) *Document {
+	inputTable := &Table{
+		Columns: []Column{
+			{Title: "Name"},
+			{Title: "Description"},
+			{Title: "Type", Align: AlignCenter},
+			{Title: "Default", Align: AlignCenter},
+			{Title: "Required", Align: AlignCenter},
+			{Title: "Sensitive", Align: AlignCenter},
+		},
+	}
+	if !m.NullableUnknown {
+		inputTable.Columns = append(inputTable.Columns, Column{Title: "Nullable", Align: AlignCenter})
+	}
+	ephemeralInputs := anyVar(m.Inputs, func(v HCLVar) bool { return v.Ephemeral })
+	if ephemeralInputs {
+		inputTable.Columns = append(inputTable.Columns, Column{Title: "Ephemeral", Align: AlignCenter})
+	}
+	var usages map[string][]Usage
+	if opts.UsedBy {
+		inputTable.Columns = append(inputTable.Columns, Column{Title: "Used by"})
+		usages = inputUsages(m)
+	}
+	for _, v := range m.Inputs {
+		row := Row{
+			Anchor: "input-" + v.Name,
+			Cells: []Cell{
+				nameCell(v, opts.SourceLinks),
+				Cell(codeSpans(v.Description)),
+				code(v.VarType),
+				code(displayDefault(v)),
+				yesNo(v.Required),
+				yesNo(v.Sensitive),
+			},
+		}
+		if !m.NullableUnknown {
+			row.Cells = append(row.Cells, yesNo(v.Nullable))
+		}
+		if ephemeralInputs {
+			row.Cells = append(row.Cells, yesNo(v.Ephemeral))
+		}
+		if opts.UsedBy {
+			row.Cells = append(row.Cells, usedBy(usages[v.Name]))
+		}
+		inputTable.Rows = append(inputTable.Rows, row)
+	}
+	var inputBlocks []Block
+	if opts.GroupBy != "" || opts.Layout == layoutSplitTable {
+		inputBlocks = groupInputs(inputTable, m.Inputs, opts.GroupBy, opts.Layout)
+	} else {
+		inputBlocks = layoutInputs(opts.Layout, inputTable, m.Inputs)
+	}
+
+	outputTable := &Table{
+		Columns: []Column{
+			{Title: "Name"},
+			{Title: "Description"},
+			{Title: "Sensitive", Align: AlignCenter},
+		},
+	}
+	outputTypes := anyVar(m.Outputs, func(v HCLVar) bool { return v.VarType != "" })
+	if outputTypes {
+		outputTable.Columns = append(outputTable.Columns, Column{Title: "Type", Align: AlignCenter})
+	}
+	ephemeralOutputs := anyVar(m.Outputs, func(v HCLVar) bool { return v.Ephemeral })
+	dependsOn := anyVar(m.Outputs, func(v HCLVar) bool { return len(v.DependsOn) > 0 })
+	if ephemeralOutputs {
+		outputTable.Columns = append(outputTable.Columns, Column{Title: "Ephemeral", Align: AlignCenter})
+	}
+	if dependsOn {
+		outputTable.Columns = append(outputTable.Columns, Column{Title: "Depends on"})
+	}
+	for _, o := range m.Outputs {
+		row := Row{
+			Anchor: "output-" + o.Name,
+			Cells: []Cell{
+				nameCell(o, opts.SourceLinks),
+				Cell(codeSpans(o.Description)),
+				yesNo(o.Sensitive),
+			},
+		}
+		if outputTypes {
+			row.Cells = append(row.Cells, code(o.VarType))
+		}
+		if ephemeralOutputs {
+			row.Cells = append(row.Cells, yesNo(o.Ephemeral))
+		}
+		if dependsOn {
+			row.Cells = append(row.Cells, codeLines(o.DependsOn))
+		}
+		outputTable.Rows = append(outputTable.Rows, row)
+	}
+
+	// Outputs get a heading each too in the document layout, so that the
+	// table of contents lists them with the inputs.
+	outputBlocks := []Block{outputTable}
+	if opts.Layout == layoutDocument {
+		outputBlocks = layoutInputs(layoutDocument, outputTable, m.Outputs)
+	}
+
+	doc := &Document{
+		Title: title,
+		Sections: []*Section{
+			{Title: "Overview"},
+			{Title: "Input", Blocks: inputBlocks},
+			{Title: "Output", Blocks: outputBlocks},
+		},
+	}
+	if len(opts.Environments) > 0 {
+		doc.Sections = append(doc.Sections, environmentSection(m.Inputs, opts.Environments))
+	}
+	if len(m.Calls) > 0 {
+		doc.Sections = append(doc.Sections, &Section{Title: "Modules", Blocks: []Block{&Table{
+			Columns: []Column{{Title: "Name"}, {Title: "Source"}, {Title: "Version", Align: AlignCenter}},
+			Rows:    callRows("", m.Calls),
+		}}})
+	}
+	if opts.Resources {
+		doc.Sections = append(doc.Sections, resourceSection(m.Resources))
+	}
+	if opts.Dependencies != nil {
+		if s := dependencySection(opts.Dependencies, m.Providers); s != nil {
+			doc.Sections = append(doc.Sections, s)
+		}
+	}
+	if opts.State != nil {
+		doc.Sections = append(doc.Sections, deploymentSections(opts.State)...)
+	}
+	if s := guarantees(m); s != nil {
+		doc.Sections = append(doc.Sections, s)
+	}
+	if s := upgradeNotes(m.Migrations); s != nil {
+		doc.Sections = append(doc.Sections, s)
+	}
+	if opts.Diagram {
+		doc.Sections = append(doc.Sections, &Section{Title: "Architecture", Blocks: []Block{moduleDiagram(m)}})
+	}
+	doc.Sections = append(doc.Sections,
+		&Section{Title: "Usage", Blocks: []Block{CodeBlock{Lang: "hcl"}}},
+		&Section{Title: "Troubleshooting"},
+	)
+	if opts.Contents {
+		contents := &Section{Title: "Contents"}
+		doc.Sections = append([]*Section{contents}, doc.Sections...)
+		anchorSections(doc)
+		contents.Blocks = []Block{tableOfContents(doc.Sections[1:])}
+	}
+	return doc
+}
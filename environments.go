@@ -0,0 +1,79 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// environment is a tfvars file configuring one deployment of a module.
+type environment struct {
+	Name string // File name without the tfvars extension, e.g. "prod".
+	Vars *tfvarsFile
+}
+
+// tfvarsExt returns the tfvars extension of a file name, or "" if it is not
+// a tfvars file.
+func tfvarsExt(name string) string {
+	for _, ext := range []string{".tfvars.json", ".tfvars"} {
+		if strings.HasSuffix(name, ext) {
+			return ext
+		}
+	}
+	return ""
+}
+
+// readEnvironments reads the tfvars files of a directory, in name order.
+func readEnvironments(dir string) ([]environment, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "read dir")
+	}
+	var envs []environment
+	for _, e := range entries {
+		ext := tfvarsExt(e.Name())
+		if e.IsDir() || ext == "" {
+			continue
+		}
+		vars, err := readTfvars(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, errors.Wrap(err, e.Name())
+		}
+		envs = append(envs, environment{Name: strings.TrimSuffix(e.Name(), ext), Vars: vars})
+	}
+	return envs, nil
+}
+
+// environmentCell returns the cell showing the value of a variable in an
+// environment.
+func environmentCell(v HCLVar, env environment) Cell {
+	val, set := env.Vars.Value(v.Name)
+	switch {
+	case !set && v.Required:
+		return Cell{{Value: "unset", Strong: true}}
+	case !set:
+		return plain("default")
+	case v.Sensitive:
+		return code(redacted)
+	}
+	return code(formatValue(val))
+}
+
+// environmentSection returns the section showing the value of each input
+// in each environment.
+func environmentSection(inputs []HCLVar, envs []environment) *Section {
+	table := &Table{Columns: []Column{{Title: "Name"}}}
+	for _, env := range envs {
+		table.Columns = append(table.Columns, Column{Title: env.Name, Align: AlignCenter})
+	}
+	for _, v := range inputs {
+		row := Row{Cells: []Cell{plain(v.Name)}}
+		for _, env := range envs {
+			row.Cells = append(row.Cells, environmentCell(v, env))
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return &Section{Title: "Configuration by Environment", Blocks: []Block{table}}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTfvarsExt(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"prod.tfvars", ".tfvars"},
+		{"prod.tfvars.json", ".tfvars.json"},
+		{"prod.auto.tfvars", ".tfvars"},
+		{"prod.json", ""},
+		{"main.tf", ""},
+	}
+	for _, tt := range tests {
+		if got := tfvarsExt(tt.name); got != tt.want {
+			t.Errorf("tfvarsExt(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEnvironmentSection(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"staging.tfvars":    "region = \"eu-west-1\"\npassword = \"x\"\n",
+		"prod.tfvars.json":  `{"region": "us-east-1", "size": 3, "zones": ["a", "b"]}`,
+		"README.md":         "Not an environment.",
+		"old.tfvars/a.tf":   "",
+		"dev.auto.tfvars":   "size = 1\n",
+		"notes.tfvars.bak":  "",
+		"staging.auto.json": "",
+	})
+	envs, err := readEnvironments(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, env := range envs {
+		names = append(names, env.Name)
+	}
+	if want := []string{"dev.auto", "prod", "staging"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("environments = %q, want %q", names, want)
+	}
+
+	inputs := []HCLVar{
+		{Name: "region", Required: true},
+		{Name: "size", Default: int64(1)},
+		{Name: "zones", Default: []interface{}{}},
+		{Name: "password", Sensitive: true, Default: ""},
+	}
+	s := environmentSection(inputs, envs)
+	table := s.Blocks[0].(*Table)
+	var columns []string
+	for _, c := range table.Columns {
+		columns = append(columns, c.Title)
+	}
+	if want := []string{"Name", "dev.auto", "prod", "staging"}; !reflect.DeepEqual(columns, want) {
+		t.Errorf("columns = %q, want %q", columns, want)
+	}
+	var rows [][]string
+	for _, r := range table.Rows {
+		var cells []string
+		for _, c := range r.Cells {
+			cell := c[0].Value
+			if c[0].Strong {
+				cell = "**" + cell + "**"
+			}
+			cells = append(cells, cell)
+		}
+		rows = append(rows, cells)
+	}
+	want := [][]string{
+		{"region", "**unset**", `"us-east-1"`, `"eu-west-1"`},
+		{"size", "1", "3", "default"},
+		{"zones", "default", `["a", "b"]`, "default"},
+		{"password", "default", "default", redacted},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q, want %q", rows, want)
+	}
+}
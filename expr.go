@@ -0,0 +1,75 @@
+package main
+
+import "strings"
+
+// unwrapExpr returns the expression of a string that consists of a single
+// Terraform 0.11 interpolation, e.g. "${var.a}" yields "var.a". Other strings
+// are returned unchanged.
+func unwrapExpr(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "${") || !strings.HasSuffix(s, "}") {
+		return s
+	}
+	if end := interpolationEnd(s, 2); end != len(s)-1 {
+		return s
+	}
+	return strings.TrimSpace(s[2 : len(s)-1])
+}
+
+// interpolationEnd returns the index of the brace closing an interpolation
+// whose body starts at start, or -1 if it is unterminated.
+func interpolationEnd(s string, start int) int {
+	depth := 0
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		case '"':
+			i = stringEnd(s, i)
+		}
+	}
+	return -1
+}
+
+// stringEnd returns the index of the quote closing the string literal that
+// opens at s[start].
+func stringEnd(s string, start int) int {
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return len(s)
+}
+
+// splitTopLevel splits an expression on sep where it appears outside
+// brackets and string literals.
+func splitTopLevel(s, sep string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case '"':
+			i = stringEnd(s, i)
+		default:
+			if depth == 0 && strings.HasPrefix(s[i:], sep) {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				i += len(sep) - 1
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(s[start:]))
+}
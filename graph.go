@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Kinds of diagram nodes.
+const (
+	nodeInput    = "input"
+	nodeLocal    = "local"
+	nodeResource = "resource"
+	nodeData     = "data"
+	nodeModule   = "module"
+	nodeOutput   = "output"
+	nodeProvider = "provider"
+	nodeCheck    = "check"
+	nodeSettings = "terraform"
+)
+
+// diagramNode is an object of the configuration.
+type diagramNode struct {
+	Address string
+	Kind    string
+}
+
+// Diagram is a graph of the objects of a module and the references
+// between them. Edges point from the referenced object to the referrer.
+type Diagram struct {
+	Nodes []diagramNode
+	Edges [][2]string
+}
+
+func (*Diagram) block() {}
+
+// moduleDiagram builds the reference graph of a module. Inputs, locals,
+// provider configurations, checks and terraform settings appear only if
+// they reference or are referenced by something.
+func moduleDiagram(m *Module) *Diagram {
+	rs := referrers(m)
+
+	known := map[string]diagramNode{}
+	for _, v := range m.Inputs {
+		known["var."+v.Name] = diagramNode{"var." + v.Name, nodeInput}
+	}
+	for _, r := range rs {
+		known[r.Address] = diagramNode{r.Address, r.Kind}
+	}
+
+	d := &Diagram{}
+	used := map[string]bool{}
+	seen := map[[2]string]bool{}
+	for _, r := range rs {
+		for _, ref := range r.Refs {
+			subject := ref.Subject()
+			if _, ok := known[subject]; !ok || subject == r.Address {
+				continue
+			}
+			edge := [2]string{subject, r.Address}
+			if !seen[edge] {
+				seen[edge] = true
+				d.Edges = append(d.Edges, edge)
+				used[subject], used[r.Address] = true, true
+			}
+		}
+	}
+
+	for _, v := range m.Inputs {
+		if used["var."+v.Name] {
+			d.Nodes = append(d.Nodes, known["var."+v.Name])
+		}
+	}
+	for _, r := range rs {
+		if r.Kind == nodeInput {
+			continue
+		}
+		if alwaysShown[r.Kind] || used[r.Address] {
+			d.Nodes = append(d.Nodes, known[r.Address])
+		}
+	}
+	return d
+}
+
+// alwaysShown are the kinds of nodes shown even if they are not connected.
+var alwaysShown = map[string]bool{
+	nodeResource: true,
+	nodeData:     true,
+	nodeModule:   true,
+	nodeOutput:   true,
+}
+
+var nonIdent = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// nodeID turns an address into a Mermaid node identifier.
+func nodeID(address string) string {
+	return nonIdent.ReplaceAllString(address, "_")
+}
+
+// mermaidShapes wrap a node label in the Mermaid syntax of its shape.
+var mermaidShapes = map[string][2]string{
+	nodeInput:    {"([", "])"},
+	nodeLocal:    {"{{", "}}"},
+	nodeResource: {"[", "]"},
+	nodeData:     {"[(", ")]"},
+	nodeModule:   {"[[", "]]"},
+	nodeOutput:   {"[/", "/]"},
+	nodeProvider: {">", "]"},
+	nodeCheck:    {"{", "}"},
+	nodeSettings: {"[\\", "\\]"},
+}
+
+// Mermaid returns the diagram as a Mermaid flowchart.
+func (d *Diagram) Mermaid() string {
+	var b bytes.Buffer
+	b.WriteString("flowchart LR")
+	for _, n := range d.Nodes {
+		shape := mermaidShapes[n.Kind]
+		fmt.Fprintf(&b, "\n  %s%s\"%s\"%s", nodeID(n.Address), shape[0], n.Address, shape[1])
+	}
+	for _, e := range d.Edges {
+		fmt.Fprintf(&b, "\n  %s --> %s", nodeID(e[0]), nodeID(e[1]))
+	}
+	return b.String()
+}
+
+// dotShapes are the Graphviz node shapes of each kind.
+var dotShapes = map[string]string{
+	nodeInput:    "ellipse",
+	nodeLocal:    "hexagon",
+	nodeResource: "box",
+	nodeData:     "cylinder",
+	nodeModule:   "component",
+	nodeOutput:   "parallelogram",
+	nodeProvider: "cds",
+	nodeCheck:    "diamond",
+	nodeSettings: "note",
+}
+
+// DOT returns the diagram as a Graphviz digraph.
+func (d *Diagram) DOT() string {
+	var b bytes.Buffer
+	b.WriteString("digraph module {\n  rankdir=LR;\n")
+	for _, n := range d.Nodes {
+		fmt.Fprintf(&b, "  %s [shape=%s];\n", strconv.Quote(n.Address), dotShapes[n.Kind])
+	}
+	for _, e := range d.Edges {
+		fmt.Fprintf(&b, "  %s -> %s;\n", strconv.Quote(e[0]), strconv.Quote(e[1]))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// dotRenderer writes the diagram of a document as a Graphviz file.
+type dotRenderer struct{}
+
+// Render implements Renderer.
+func (dotRenderer) Render(w io.Writer, doc *Document) error {
+	d := findDiagram(doc.Sections)
+	if d == nil {
+		return errors.New("document has no diagram")
+	}
+	_, err := io.WriteString(w, d.DOT())
+	return errors.Wrap(err, "write stream")
+}
+
+// findDiagram returns the first diagram in a list of sections.
+func findDiagram(sections []*Section) *Diagram {
+	for _, s := range sections {
+		for _, blk := range s.Blocks {
+			switch blk := blk.(type) {
+			case *Diagram:
+				return blk
+			case *Section:
+				if d := findDiagram([]*Section{blk}); d != nil {
+					return d
+				}
+			}
+		}
+	}
+	return nil
+}
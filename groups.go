@@ -0,0 +1,118 @@
+package main
+
+import (
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// Ways of grouping inputs.
+const (
+	groupByFile       = "file"
+	groupByAnnotation = "annotation"
+	groupByPrefix     = "prefix"
+)
+
+// groupings are the valid values of the -group flag.
+var groupings = []string{groupByFile, groupByAnnotation, groupByPrefix}
+
+// Titles of the groups that are not derived from the inputs.
+const (
+	requiredGroup = "Required inputs"
+	optionalGroup = "Optional inputs"
+	otherGroup    = "Other"
+)
+
+// titleWords turns a file name or a name prefix into a title, e.g.
+// "network_acl" into "Network Acl".
+func titleWords(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool { return r == '_' || r == '-' || r == '.' })
+	for i, w := range words {
+		r, size := utf8.DecodeRuneInString(w)
+		words[i] = string(unicode.ToUpper(r)) + w[size:]
+	}
+	return strings.Join(words, " ")
+}
+
+// checkGroupAnnotations rejects "# @group" annotations naming a group that
+// is not derived from the inputs, which would merge the annotated inputs
+// into it.
+func checkGroupAnnotations(inputs []HCLVar) error {
+	for _, v := range inputs {
+		for _, reserved := range []string{requiredGroup, otherGroup} {
+			if strings.EqualFold(v.Group, reserved) {
+				return errors.Errorf("group %q of variable %q is reserved", v.Group, v.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// inputGroup returns the title of the group of an optional input. Without
+// a grouping, optional inputs form a single group.
+func inputGroup(v HCLVar, by string) string {
+	var title string
+	switch by {
+	case "":
+		return optionalGroup
+	case groupByFile:
+		name := path.Base(v.Pos.Filename)
+		title = titleWords(strings.TrimSuffix(strings.TrimSuffix(name, ".json"), ".tf"))
+		if v.Pos.Filename == "" {
+			title = ""
+		}
+	case groupByAnnotation:
+		title = v.Group
+	case groupByPrefix:
+		title = titleWords(strings.SplitN(v.Name, "_", 2)[0])
+	}
+	if title == "" {
+		return otherGroup
+	}
+	return title
+}
+
+// groupInputs splits the rows of the input table, which are in the order
+// of inputs, into sections laid out each in a layout. Required inputs come
+// first, then the groups in the order they first appear, and inputs without
+// a group last. A name prefix shared by a single input forms no group.
+func groupInputs(table *Table, inputs []HCLVar, by, layout string) []Block {
+	groups := make([]string, len(inputs))
+	count := map[string]int{}
+	for i, v := range inputs {
+		groups[i] = requiredGroup
+		if !v.Required {
+			groups[i] = inputGroup(v, by)
+		}
+		count[groups[i]]++
+	}
+
+	var titles []string
+	rows := map[string][]Row{}
+	vars := map[string][]HCLVar{}
+	for i, v := range inputs {
+		title := groups[i]
+		if by == groupByPrefix && title != requiredGroup && count[title] < 2 {
+			title = otherGroup
+		}
+		if _, ok := rows[title]; !ok && title != requiredGroup && title != otherGroup {
+			titles = append(titles, title)
+		}
+		rows[title] = append(rows[title], table.Rows[i])
+		vars[title] = append(vars[title], v)
+	}
+	titles = append(append([]string{requiredGroup}, titles...), otherGroup)
+
+	var blocks []Block
+	for _, title := range titles {
+		if len(rows[title]) == 0 {
+			continue
+		}
+		group := &Table{Columns: table.Columns, Rows: rows[title]}
+		blocks = append(blocks, &Section{Title: title, Blocks: layoutInputs(layout, group, vars[title])})
+	}
+	return blocks
+}
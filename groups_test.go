@@ -0,0 +1,136 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestTitleWords(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"network", "Network"},
+		{"network_acl", "Network Acl"},
+		{"load-balancer.rules", "Load Balancer Rules"},
+		{"__vpc__", "Vpc"},
+		{"ébauche_réseau", "Ébauche Réseau"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := titleWords(tt.in); got != tt.want {
+			t.Errorf("titleWords(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestInputGroup(t *testing.T) {
+	tests := []struct {
+		v    HCLVar
+		by   string
+		want string
+	}{
+		{HCLVar{Name: "vpc_cidr"}, "", optionalGroup},
+		{HCLVar{Name: "vpc_cidr", Pos: hcl.Range{Filename: "modules/net/network_acl.tf"}}, groupByFile, "Network Acl"},
+		{HCLVar{Name: "vpc_cidr", Pos: hcl.Range{Filename: "variables.tf.json"}}, groupByFile, "Variables"},
+		{HCLVar{Name: "vpc_cidr"}, groupByFile, otherGroup},
+		{HCLVar{Name: "vpc_cidr", Group: "Networking"}, groupByAnnotation, "Networking"},
+		{HCLVar{Name: "vpc_cidr"}, groupByAnnotation, otherGroup},
+		{HCLVar{Name: "vpc_cidr"}, groupByPrefix, "Vpc"},
+		{HCLVar{Name: "name"}, groupByPrefix, "Name"},
+	}
+	for _, tt := range tests {
+		if got := inputGroup(tt.v, tt.by); got != tt.want {
+			t.Errorf("inputGroup(%s, %q) = %q, want %q", tt.v.Name, tt.by, got, tt.want)
+		}
+	}
+}
+
+func TestGroupInputs(t *testing.T) {
+	tests := []struct {
+		name   string
+		inputs []HCLVar
+		by     string
+		want   map[string][]string
+		titles []string
+	}{
+		{
+			name: "prefix",
+			inputs: []HCLVar{
+				{Name: "vpc_cidr"},
+				{Name: "region", Required: true},
+				{Name: "name"},
+				{Name: "subnet_ids"},
+				{Name: "vpc_tags"},
+				{Name: "subnet_tags"},
+			},
+			by:     groupByPrefix,
+			titles: []string{requiredGroup, "Vpc", "Subnet", otherGroup},
+			want: map[string][]string{
+				requiredGroup: {"region"},
+				"Vpc":         {"vpc_cidr", "vpc_tags"},
+				"Subnet":      {"subnet_ids", "subnet_tags"},
+				otherGroup:    {"name"},
+			},
+		},
+		{
+			name: "annotation",
+			inputs: []HCLVar{
+				{Name: "a"},
+				{Name: "b", Group: "Network"},
+				{Name: "c", Group: "Access"},
+				{Name: "d", Group: "Network", Required: true},
+			},
+			by:     groupByAnnotation,
+			titles: []string{requiredGroup, "Network", "Access", otherGroup},
+			want: map[string][]string{
+				requiredGroup: {"d"},
+				"Network":     {"b"},
+				"Access":      {"c"},
+				otherGroup:    {"a"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		table := &Table{}
+		for _, v := range tt.inputs {
+			table.Rows = append(table.Rows, Row{Anchor: v.Name})
+		}
+		var titles []string
+		got := map[string][]string{}
+		for _, b := range groupInputs(table, tt.inputs, tt.by, layoutTable) {
+			s := b.(*Section)
+			titles = append(titles, s.Title)
+			for _, r := range s.Blocks[0].(*Table).Rows {
+				got[s.Title] = append(got[s.Title], r.Anchor)
+			}
+		}
+		if !reflect.DeepEqual(titles, tt.titles) {
+			t.Errorf("%s: groups = %q, want %q", tt.name, titles, tt.titles)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: rows = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCheckGroupAnnotations(t *testing.T) {
+	tests := []struct {
+		group string
+		ok    bool
+	}{
+		{"", true},
+		{"Network", true},
+		{"Other", false},
+		{"other", false},
+		{"Required inputs", false},
+		{"Optional inputs", true},
+	}
+	for _, tt := range tests {
+		err := checkGroupAnnotations([]HCLVar{{Name: "v", Group: tt.group}})
+		if (err == nil) != tt.ok {
+			t.Errorf("checkGroupAnnotations(%q) = %v, want ok %t", tt.group, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,703 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+	"github.com/pkg/errors"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// configFile is a parsed configuration file, in the native syntax or in
+// JSON.
+type configFile struct {
+	Name string // File name, recorded in the positions of its blocks.
+	Src  []byte
+	Body hcl.Body
+	// Tokens are the tokens of a native syntax file, which hold its
+	// comments.
+	Tokens hclsyntax.Tokens
+}
+
+// parseHCLFile reads and parses an HCL file.
+func parseHCLFile(path string) (*configFile, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "read file")
+	}
+	return parseHCL(raw, path)
+}
+
+// parseHCL parses HCL native syntax, or JSON if the file name ends in
+// ".json".
+func parseHCL(raw []byte, name string) (*configFile, error) {
+	var (
+		f     *hcl.File
+		diags hcl.Diagnostics
+	)
+	cf := &configFile{Name: name, Src: raw}
+	if strings.HasSuffix(name, ".json") {
+		f, diags = hcljson.Parse(raw, name)
+	} else {
+		f, diags = hclsyntax.ParseConfig(raw, name, hcl.InitialPos)
+		cf.Tokens, _ = hclsyntax.LexConfig(raw, name, hcl.InitialPos)
+	}
+	if diags.HasErrors() {
+		return nil, errors.Wrap(diags, "parse")
+	}
+	cf.Body = f.Body
+	return cf, nil
+}
+
+// fileSchema lists the top-level blocks of a configuration file.
+var fileSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "terraform"},
+		{Type: "variable", LabelNames: []string{"name"}},
+		{Type: "output", LabelNames: []string{"name"}},
+		{Type: "provider", LabelNames: []string{"name"}},
+		{Type: "resource", LabelNames: []string{"type", "name"}},
+		{Type: "data", LabelNames: []string{"type", "name"}},
+		{Type: "ephemeral", LabelNames: []string{"type", "name"}},
+		{Type: "module", LabelNames: []string{"name"}},
+		{Type: "locals"},
+		{Type: migrationMoved},
+		{Type: migrationRemoved},
+		{Type: migrationImport},
+		{Type: "check", LabelNames: []string{"name"}},
+	},
+}
+
+// blocks returns the top-level blocks of the given type.
+func (f *configFile) blocks(typ string) (hcl.Blocks, error) {
+	content, _, diags := f.Body.PartialContent(fileSchema)
+	if diags.HasErrors() {
+		return nil, errors.Wrap(diags, "decode")
+	}
+	return content.Blocks.OfType(typ), nil
+}
+
+// attributes returns the named attributes of a body. Attributes that are
+// not set are missing from the result.
+func attributes(body hcl.Body, names ...string) hcl.Attributes {
+	schema := &hcl.BodySchema{}
+	for _, name := range names {
+		schema.Attributes = append(schema.Attributes, hcl.AttributeSchema{Name: name})
+	}
+	content, _, _ := body.PartialContent(schema)
+	if content == nil {
+		return hcl.Attributes{}
+	}
+	return content.Attributes
+}
+
+// nestedBlocks returns the blocks of the given type in a body.
+func nestedBlocks(body hcl.Body, typ string, labels ...string) hcl.Blocks {
+	schema := &hcl.BodySchema{Blocks: []hcl.BlockHeaderSchema{{Type: typ, LabelNames: labels}}}
+	content, _, _ := body.PartialContent(schema)
+	if content == nil {
+		return nil
+	}
+	return content.Blocks
+}
+
+// sortedAttributes returns attributes in the order of the source.
+func sortedAttributes(attrs hcl.Attributes) []*hcl.Attribute {
+	list := make([]*hcl.Attribute, 0, len(attrs))
+	for _, a := range attrs {
+		list = append(list, a)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Range.Start.Byte < list[j].Range.Start.Byte })
+	return list
+}
+
+// ctyValue converts a value to a Go value: string, int64, float64, bool,
+// []interface{} or map[string]interface{}. Null and unknown values convert
+// to nil.
+func ctyValue(v cty.Value) interface{} {
+	if v.IsNull() || !v.IsKnown() {
+		return nil
+	}
+	t := v.Type()
+	switch {
+	case t == cty.String:
+		return v.AsString()
+	case t == cty.Number:
+		bf := v.AsBigFloat()
+		if i, acc := bf.Int64(); acc == big.Exact {
+			return i
+		}
+		f, _ := bf.Float64()
+		return f
+	case t == cty.Bool:
+		return v.True()
+	case t.IsListType(), t.IsSetType(), t.IsTupleType():
+		list := make([]interface{}, 0, v.LengthInt())
+		for it := v.ElementIterator(); it.Next(); {
+			_, e := it.Element()
+			list = append(list, ctyValue(e))
+		}
+		return list
+	case t.IsMapType(), t.IsObjectType():
+		m := make(map[string]interface{}, v.LengthInt())
+		for it := v.ElementIterator(); it.Next(); {
+			k, e := it.Element()
+			m[k.AsString()] = ctyValue(e)
+		}
+		return m
+	}
+	return nil
+}
+
+// exprValue evaluates an expression that references nothing, such as a
+// default, and converts its value with ctyValue.
+func exprValue(expr hcl.Expression) (interface{}, error) {
+	v, diags := expr.Value(nil)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return ctyValue(v), nil
+}
+
+// attrValue returns the value of an attribute, or nil if it is not set or
+// is not a constant.
+func attrValue(attrs hcl.Attributes, name string) interface{} {
+	a, ok := attrs[name]
+	if !ok {
+		return nil
+	}
+	v, _ := exprValue(a.Expr)
+	return v
+}
+
+// attrString returns the string value of the named attribute, or "".
+func attrString(attrs hcl.Attributes, name string) string {
+	s, _ := attrValue(attrs, name).(string)
+	return s
+}
+
+// attrBool returns the boolean value of the named attribute, or false.
+func attrBool(attrs hcl.Attributes, name string) bool {
+	switch v := attrValue(attrs, name).(type) {
+	case bool:
+		return v
+	case string:
+		// Terraform 0.11 accepts quoted booleans.
+		b, _ := strconv.ParseBool(v)
+		return b
+	}
+	return false
+}
+
+// text returns the source of an expression. A string that consists of a
+// single interpolation, such as "${var.a}", yields the interpolated
+// expression, and JSON strings yield the expression they hold.
+func (f *configFile) text(expr hcl.Expression) string {
+	if w, ok := expr.(*hclsyntax.TemplateWrapExpr); ok {
+		expr = w.Wrapped
+	}
+	raw := expr.Range().SliceBytes(f.Src)
+	if _, ok := expr.(hclsyntax.Expression); !ok {
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			return unwrapExpr(s)
+		}
+	}
+	return string(raw)
+}
+
+// attrText returns the source of the named attribute, or "".
+func (f *configFile) attrText(attrs hcl.Attributes, name string) string {
+	a, ok := attrs[name]
+	if !ok {
+		return ""
+	}
+	return f.text(a.Expr)
+}
+
+// attrMessage returns the value of a string attribute that may interpolate
+// values, such as an error message, or its source if it does.
+func (f *configFile) attrMessage(attrs hcl.Attributes, name string) string {
+	if _, ok := attrs[name]; !ok {
+		return ""
+	}
+	if s, ok := attrValue(attrs, name).(string); ok {
+		return s
+	}
+	return f.attrText(attrs, name)
+}
+
+// leadComments returns the text of the comments on the lines directly
+// above a line, which document the block starting there. Comments that
+// follow code on the same line are not lead comments.
+func (f *configFile) leadComments(line int) []string {
+	var comments []string
+	for i := len(f.Tokens) - 1; i >= 0; i-- {
+		t := f.Tokens[i]
+		if t.Type != hclsyntax.TokenComment {
+			continue
+		}
+		end := t.Range.End.Line
+		if bytes.HasSuffix(t.Bytes, []byte("\n")) {
+			end--
+		}
+		if end >= line {
+			continue
+		}
+		ownLine := i == 0 || f.Tokens[i-1].Type == hclsyntax.TokenNewline ||
+			f.Tokens[i-1].Type == hclsyntax.TokenComment && bytes.HasSuffix(f.Tokens[i-1].Bytes, []byte("\n"))
+		if end != line-1 || !ownLine {
+			break
+		}
+		comments = append([]string{string(bytes.TrimSpace(t.Bytes))}, comments...)
+		line = t.Range.Start.Line
+	}
+	return comments
+}
+
+// formatValue renders a value converted by ctyValue as an HCL literal.
+// Lists of scalars stay on one line; objects are expanded.
+func formatValue(v interface{}) string {
+	return formatIndented(v, "")
+}
+
+func formatIndented(v interface{}, indent string) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strconv.Quote(v)
+	case []interface{}:
+		if len(v) == 0 {
+			return "[]"
+		}
+		elems := make([]string, 0, len(v))
+		multiline := false
+		for _, e := range v {
+			s := formatIndented(e, indent+"  ")
+			multiline = multiline || strings.Contains(s, "\n")
+			elems = append(elems, s)
+		}
+		if !multiline {
+			return "[" + strings.Join(elems, ", ") + "]"
+		}
+		return "[\n" + indent + "  " + strings.Join(elems, ",\n"+indent+"  ") + ",\n" + indent + "]"
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return "{}"
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var b strings.Builder
+		b.WriteString("{\n")
+		for _, k := range keys {
+			fmt.Fprintf(&b, "%s  %s = %s\n", indent, formatKey(k), formatIndented(v[k], indent+"  "))
+		}
+		b.WriteString(indent + "}")
+		return b.String()
+	}
+	return fmt.Sprint(v)
+}
+
+// formatInline formats a value as an HCL literal on one line, e.g.
+// { name = "a", ports = [80, 443] }.
+func formatInline(v interface{}) string {
+	switch v := v.(type) {
+	case []interface{}:
+		elems := make([]string, 0, len(v))
+		for _, e := range v {
+			elems = append(elems, formatInline(e))
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return "{}"
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		attrs := make([]string, 0, len(keys))
+		for _, k := range keys {
+			attrs = append(attrs, formatKey(k)+" = "+formatInline(v[k]))
+		}
+		return "{ " + strings.Join(attrs, ", ") + " }"
+	}
+	return formatValue(v)
+}
+
+// formatKey quotes an object key unless it is a valid identifier.
+func formatKey(k string) string {
+	for i, r := range k {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && (r == '-' || r >= '0' && r <= '9')) {
+			return strconv.Quote(k)
+		}
+	}
+	if k == "" {
+		return `""`
+	}
+	return k
+}
+
+// typeText returns the type constraint of a variable, formatted on one
+// line. Terraform 0.11 and JSON configuration quote the constraint, which
+// is returned as written.
+func (f *configFile) typeText(attrs hcl.Attributes) string {
+	a, ok := attrs["type"]
+	if !ok {
+		return ""
+	}
+	if s, ok := attrValue(attrs, "type").(string); ok {
+		return s
+	}
+	if t, err := typeExpr(a.Expr); err == nil {
+		return t.String()
+	}
+	return f.attrText(attrs, "type")
+}
+
+// decodeVars decodes the top-level blocks of the given kind, e.g. "variable"
+// or "output", into a table.
+func decodeVars(f *configFile, kind string) ([]HCLVar, error) {
+	blocks, err := f.blocks(kind)
+	if err != nil {
+		return nil, err
+	}
+	hclVars := make([]HCLVar, 0, len(blocks))
+
+	for _, block := range blocks {
+		attrs := attributes(block.Body, "description", "type", "default", "sensitive",
+			"nullable", "ephemeral", "value", "depends_on")
+		comments := f.leadComments(block.DefRange.Start.Line)
+
+		var hclVar HCLVar
+		hclVar.Name = block.Labels[0]
+		hclVar.Pos = block.DefRange
+		hclVar.Description = attrString(attrs, "description")
+		hclVar.VarType = f.typeText(attrs)
+		if def, ok := attrs["default"]; ok {
+			v, err := exprValue(def.Expr)
+			if err != nil {
+				return nil, errors.Wrapf(err, "default of %s %q", kind, hclVar.Name)
+			}
+			hclVar.Default = v
+			hclVar.DefaultVal = formatValue(v)
+		}
+		_, hasDefault := attrs["default"]
+		hclVar.Required = kind == "variable" && !hasDefault
+		hclVar.Sensitive = attrBool(attrs, "sensitive")
+		hclVar.Ephemeral = attrBool(attrs, "ephemeral")
+		hclVar.Deprecated, hclVar.Replacement = deprecation(comments, hclVar.Description)
+		hclVar.Group = groupAnnotation(comments)
+		if kind == "output" {
+			hclVar.Value = f.attrText(attrs, "value")
+			hclVar.Refs = bodyRefs(block.Body)
+			if a, ok := attrs["depends_on"]; ok {
+				hclVar.DependsOn = traversalList(a.Expr)
+			}
+		}
+		if kind == "variable" {
+			_, set := attrs["nullable"]
+			hclVar.Nullable = !set || attrBool(attrs, "nullable")
+		}
+		hclVar.Validations = f.decodeConditions(block.Body, "validation")
+		if kind == "variable" {
+			for _, v := range nestedBlocks(block.Body, "validation") {
+				hclVar.Refs = append(hclVar.Refs, bodyRefs(v.Body)...)
+			}
+		}
+		hclVar.Preconditions = f.decodeConditions(block.Body, "precondition")
+
+		hclVars = append(hclVars, hclVar)
+	}
+
+	return hclVars, nil
+}
+
+// traversalList returns the addresses in a list of references, such as
+// the value of depends_on.
+func traversalList(expr hcl.Expression) []string {
+	exprs, _ := hcl.ExprList(expr)
+	var addrs []string
+	for _, e := range exprs {
+		if t, diags := hcl.AbsTraversalForExpr(e); !diags.HasErrors() {
+			addrs = append(addrs, traversalString(t))
+		}
+	}
+	return addrs
+}
+
+// decodeConditions decodes the condition blocks of the given kind, e.g.
+// "validation" or "precondition".
+func (f *configFile) decodeConditions(body hcl.Body, kind string) []Validation {
+	var conds []Validation
+	for _, block := range nestedBlocks(body, kind) {
+		attrs := attributes(block.Body, "condition", "error_message")
+		conds = append(conds, Validation{
+			Condition:    f.attrText(attrs, "condition"),
+			ErrorMessage: f.attrMessage(attrs, "error_message"),
+		})
+	}
+	return conds
+}
+
+var (
+	deprecatedNote  = regexp.MustCompile(`(?i)^(?:#|//|/\*)?\s*@?deprecated\b`)
+	replacementNote = regexp.MustCompile("(?i)\\b(?:use|replaced by|superseded by)\\s+`?(?:var\\.)?([A-Za-z_][A-Za-z0-9_-]*)")
+)
+
+// deprecation reports whether a block is deprecated, by a lead comment or a
+// description starting with "Deprecated", and the name of its replacement
+// if the note names one, as in "# Deprecated: use new_name instead."
+func deprecation(comments []string, description string) (bool, string) {
+	notes := append([]string{description}, comments...)
+	for _, note := range notes {
+		if !deprecatedNote.MatchString(note) {
+			continue
+		}
+		if m := replacementNote.FindStringSubmatch(note); m != nil {
+			return true, m[1]
+		}
+		return true, ""
+	}
+	return false, ""
+}
+
+var groupNote = regexp.MustCompile(`@group\s+(.*\S)`)
+
+// groupAnnotation returns the group named by a "# @group Name" lead
+// comment, or "".
+func groupAnnotation(comments []string) string {
+	for _, c := range comments {
+		if m := groupNote.FindStringSubmatch(c); m != nil {
+			return strings.TrimSpace(strings.TrimSuffix(m[1], "*/"))
+		}
+	}
+	return ""
+}
+
+// objectAttrs returns the values of the attributes of an object
+// constructor, such as the requirement of a provider, by name.
+func objectAttrs(expr hcl.Expression) map[string]hcl.Expression {
+	pairs, diags := hcl.ExprMap(expr)
+	if diags.HasErrors() {
+		return nil
+	}
+	attrs := make(map[string]hcl.Expression, len(pairs))
+	for _, pair := range pairs {
+		key := hcl.ExprAsKeyword(pair.Key)
+		if key == "" {
+			v, _ := exprValue(pair.Key)
+			key, _ = v.(string)
+		}
+		attrs[key] = pair.Value
+	}
+	return attrs
+}
+
+// decodeRequirements adds the Terraform and provider version requirements
+// of a file to a module.
+func decodeRequirements(f *configFile, m *Module) error {
+	terraform, err := f.blocks("terraform")
+	if err != nil {
+		return err
+	}
+	for _, tf := range terraform {
+		m.SettingsRefs = append(m.SettingsRefs, bodyRefs(tf.Body)...)
+		if v := attrString(attributes(tf.Body, "required_version"), "required_version"); v != "" {
+			m.RequiredVersion = v
+		}
+		for _, rp := range nestedBlocks(tf.Body, "required_providers") {
+			attrs, _ := rp.Body.JustAttributes()
+			for _, a := range sortedAttributes(attrs) {
+				p := ProviderRequirement{Name: a.Name}
+				if v, err := exprValue(a.Expr); err == nil {
+					// Terraform 0.12 sets the version constraint only.
+					p.Version, _ = v.(string)
+				}
+				if obj := objectAttrs(a.Expr); obj != nil {
+					if e, ok := obj["source"]; ok {
+						v, _ := exprValue(e)
+						p.Source, _ = v.(string)
+					}
+					if e, ok := obj["version"]; ok {
+						v, _ := exprValue(e)
+						p.Version, _ = v.(string)
+					}
+				}
+				m.addProvider(p)
+			}
+		}
+	}
+	providers, err := f.blocks("provider")
+	if err != nil {
+		return err
+	}
+	for _, block := range providers {
+		attrs := attributes(block.Body, "version", "alias")
+		m.addProvider(ProviderRequirement{
+			Name:    block.Labels[0],
+			Version: attrString(attrs, "version"),
+		})
+		m.ProviderConfigs = append(m.ProviderConfigs, ProviderConfig{
+			Name:  block.Labels[0],
+			Alias: attrString(attrs, "alias"),
+			Refs:  bodyRefs(block.Body),
+			Pos:   block.DefRange,
+		})
+	}
+	return nil
+}
+
+// decodeResources decodes the resource and data blocks of a file.
+func decodeResources(f *configFile) ([]Resource, error) {
+	var resources []Resource
+	for _, mode := range []string{"resource", "data", "ephemeral"} {
+		blocks, err := f.blocks(mode)
+		if err != nil {
+			return nil, err
+		}
+		for _, block := range blocks {
+			r := Resource{
+				Mode: "managed",
+				Type: block.Labels[0],
+				Name: block.Labels[1],
+				Refs: bodyRefs(block.Body),
+				Pos:  block.DefRange,
+			}
+			if mode != "resource" {
+				r.Mode = mode
+			}
+			for _, lc := range nestedBlocks(block.Body, "lifecycle") {
+				r.Preconditions = append(r.Preconditions, f.decodeConditions(lc.Body, "precondition")...)
+				r.Postconditions = append(r.Postconditions, f.decodeConditions(lc.Body, "postcondition")...)
+			}
+			resources = append(resources, r)
+		}
+	}
+	return resources, nil
+}
+
+// decodeCalls decodes the module blocks of a file.
+func decodeCalls(f *configFile) ([]ModuleCall, error) {
+	blocks, err := f.blocks("module")
+	if err != nil {
+		return nil, err
+	}
+	var calls []ModuleCall
+	for _, block := range blocks {
+		attrs := attributes(block.Body, "source", "version")
+		calls = append(calls, ModuleCall{
+			Name:    block.Labels[0],
+			Source:  attrString(attrs, "source"),
+			Version: attrString(attrs, "version"),
+			Refs:    bodyRefs(block.Body),
+			Pos:     block.DefRange,
+		})
+	}
+	return calls, nil
+}
+
+// decodeLocals decodes the locals blocks of a file.
+func decodeLocals(f *configFile) ([]Local, error) {
+	blocks, err := f.blocks("locals")
+	if err != nil {
+		return nil, err
+	}
+	var locals []Local
+	for _, block := range blocks {
+		attrs, diags := block.Body.JustAttributes()
+		if diags.HasErrors() {
+			return nil, errors.Wrap(diags, "decode locals")
+		}
+		for _, a := range sortedAttributes(attrs) {
+			locals = append(locals, Local{
+				Name: a.Name,
+				Refs: exprRefs(a.Expr, a.Name),
+				Pos:  a.Range,
+			})
+		}
+	}
+	return locals, nil
+}
+
+// address returns the address an attribute refers to, such as the from
+// address of a moved block, or its source if it is not a reference.
+func (f *configFile) address(attrs hcl.Attributes, name string) string {
+	a, ok := attrs[name]
+	if !ok {
+		return ""
+	}
+	if t, diags := hcl.AbsTraversalForExpr(a.Expr); !diags.HasErrors() {
+		return traversalString(t)
+	}
+	return f.text(a.Expr)
+}
+
+// decodeMigrations decodes the moved, removed and import blocks of a file.
+func decodeMigrations(f *configFile) ([]Migration, error) {
+	var migrations []Migration
+	for _, kind := range []string{migrationMoved, migrationRemoved, migrationImport} {
+		blocks, err := f.blocks(kind)
+		if err != nil {
+			return nil, err
+		}
+		for _, block := range blocks {
+			attrs := attributes(block.Body, "from", "to", "id")
+			mig := Migration{
+				Kind:    kind,
+				From:    f.address(attrs, "from"),
+				To:      f.address(attrs, "to"),
+				ID:      f.attrMessage(attrs, "id"),
+				Destroy: true,
+				Pos:     block.DefRange,
+			}
+			for _, lc := range nestedBlocks(block.Body, "lifecycle") {
+				if lcAttrs := attributes(lc.Body, "destroy"); lcAttrs["destroy"] != nil {
+					mig.Destroy = attrBool(lcAttrs, "destroy")
+				}
+			}
+			migrations = append(migrations, mig)
+		}
+	}
+	return migrations, nil
+}
+
+// decodeChecks decodes the check blocks of a file.
+func decodeChecks(f *configFile) ([]Check, error) {
+	blocks, err := f.blocks("check")
+	if err != nil {
+		return nil, err
+	}
+	var checks []Check
+	for _, block := range blocks {
+		c := Check{
+			Name:    block.Labels[0],
+			Asserts: f.decodeConditions(block.Body, "assert"),
+			Refs:    bodyRefs(block.Body),
+			Pos:     block.DefRange,
+		}
+		for _, data := range nestedBlocks(block.Body, "data", "type", "name") {
+			for _, lc := range nestedBlocks(data.Body, "lifecycle") {
+				c.Preconditions = append(c.Preconditions, f.decodeConditions(lc.Body, "precondition")...)
+				c.Postconditions = append(c.Postconditions, f.decodeConditions(lc.Body, "postcondition")...)
+			}
+		}
+		checks = append(checks, c)
+	}
+	return checks, nil
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// htmlPage renders a document as a self-contained HTML page with sortable,
+// filterable tables. All styles and scripts are inlined.
+type htmlPage struct{}
+
+// htmlCollapseLen is the length above which code values are collapsed.
+const htmlCollapseLen = 60
+
+var pageTmpl = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; line-height: 1.5; color: #24292e; max-width: 1100px; margin: 0 auto; padding: 2em; }
+h1, h2, h3, h4 { border-bottom: 1px solid #eaecef; padding-bottom: .3em; }
+a.anchor { color: #959da5; text-decoration: none; margin-left: .3em; visibility: hidden; }
+h2:hover a.anchor, h3:hover a.anchor, h4:hover a.anchor, tr:hover a.anchor { visibility: visible; }
+code, pre { font-family: SFMono-Regular, Consolas, Menlo, monospace; font-size: 85%; background: #f6f8fa; border-radius: 3px; }
+code { padding: .2em .4em; }
+pre { padding: 1em; overflow: auto; }
+pre code { padding: 0; }
+table { border-collapse: collapse; width: 100%; margin: 1em 0; }
+th, td { border: 1px solid #dfe2e5; padding: 6px 13px; text-align: left; vertical-align: top; }
+th.center, td.center { text-align: center; }
+th { background: #f6f8fa; cursor: pointer; user-select: none; white-space: nowrap; }
+th[aria-sort=ascending]::after { content: " \25B2"; }
+th[aria-sort=descending]::after { content: " \25BC"; }
+tr:target { background: #fffbdd; }
+details summary { cursor: pointer; }
+#filter { width: 100%; box-sizing: border-box; padding: .5em; font-size: 1em; margin: 1em 0; border: 1px solid #dfe2e5; border-radius: 3px; }
+</style>
+</head>
+<body>
+<input type="search" id="filter" placeholder="Filter inputs and outputs" aria-label="Filter inputs and outputs">
+{{.Body}}
+<script>
+(function () {
+  document.querySelectorAll("table.sortable").forEach(function (table) {
+    var headers = table.querySelectorAll("thead th");
+    headers.forEach(function (th, col) {
+      th.addEventListener("click", function () {
+        var asc = th.getAttribute("aria-sort") !== "ascending";
+        headers.forEach(function (h) { h.removeAttribute("aria-sort"); });
+        th.setAttribute("aria-sort", asc ? "ascending" : "descending");
+        var body = table.tBodies[0];
+        var rows = Array.prototype.slice.call(body.rows);
+        rows.sort(function (a, b) {
+          var x = a.cells[col].textContent.trim(), y = b.cells[col].textContent.trim();
+          var c = (x !== "" && y !== "" && !isNaN(x) && !isNaN(y)) ? x - y : x.localeCompare(y);
+          return asc ? c : -c;
+        });
+        rows.forEach(function (r) { body.appendChild(r); });
+      });
+    });
+  });
+  document.getElementById("filter").addEventListener("input", function (e) {
+    var q = e.target.value.toLowerCase();
+    document.querySelectorAll("table.sortable tbody tr").forEach(function (tr) {
+      tr.hidden = q !== "" && tr.textContent.toLowerCase().indexOf(q) === -1;
+    });
+  });
+})();
+</script>
+</body>
+</html>
+`))
+
+// Render implements Renderer.
+func (htmlPage) Render(w io.Writer, doc *Document) error {
+	var body bytes.Buffer
+	if err := (markupRenderer{htmlMarkup{}}).Render(&body, doc); err != nil {
+		return err
+	}
+	err := pageTmpl.Execute(w, struct {
+		Title string
+		Body  template.HTML
+	}{doc.Title, template.HTML(body.String())})
+	return errors.Wrap(err, "execute page template")
+}
+
+// htmlMarkup emits the HTML body of a page.
+type htmlMarkup struct{}
+
+var esc = html.EscapeString
+
+func (htmlMarkup) title(b *bytes.Buffer, title string) {
+	fmt.Fprintf(b, "<h1>%s</h1>\n", esc(title))
+}
+
+func (htmlMarkup) heading(b *bytes.Buffer, level int, title, anchor string) {
+	id := esc(anchor)
+	fmt.Fprintf(b, "<h%d id=\"%s\">%s<a class=\"anchor\" href=\"#%s\">#</a></h%d>\n", level+1, id, esc(title), id, level+1)
+}
+
+func (h htmlMarkup) text(t Text) string {
+	if t.Link != "" {
+		link := t.Link
+		t.Link = ""
+		return "<a href=\"" + esc(link) + "\">" + h.text(t) + "</a>"
+	}
+	if t.Strike {
+		t.Strike = false
+		return "<del>" + h.text(t) + "</del>"
+	}
+	if t.Strong {
+		return "<strong>" + esc(t.Value) + "</strong>"
+	}
+	if !t.Code {
+		return strings.Replace(esc(t.Value), "\n", "<br>", -1)
+	}
+	if len(t.Value) <= htmlCollapseLen && !strings.Contains(t.Value, "\n") {
+		return "<code>" + esc(t.Value) + "</code>"
+	}
+	summary := strings.SplitN(t.Value, "\n", 2)[0]
+	if r := []rune(summary); len(r) > htmlCollapseLen {
+		summary = string(r[:htmlCollapseLen])
+	}
+	return fmt.Sprintf("<details><summary><code>%s…</code></summary><pre><code>%s</code></pre></details>", esc(summary), esc(t.Value))
+}
+
+func (h htmlMarkup) paragraph(b *bytes.Buffer, p Paragraph) {
+	fmt.Fprintf(b, "<p>%s</p>\n", joinText(p, h.text))
+}
+
+func (h htmlMarkup) list(b *bytes.Buffer, l List) {
+	b.WriteString("<ul>\n")
+	for _, item := range l {
+		fmt.Fprintf(b, "<li>%s</li>\n", joinText(item, h.text))
+	}
+	b.WriteString("</ul>\n")
+}
+
+func (h htmlMarkup) contents(b *bytes.Buffer, c Contents) {
+	if len(c) == 0 {
+		return
+	}
+	b.WriteString("<ul>\n")
+	for _, e := range c {
+		fmt.Fprintf(b, "<li>%s\n", h.text(Text{Value: e.Title, Link: "#" + e.Anchor}))
+		h.contents(b, e.Entries)
+		b.WriteString("</li>\n")
+	}
+	b.WriteString("</ul>\n")
+}
+
+func (h htmlMarkup) table(b *bytes.Buffer, t *Table) {
+	b.WriteString("<table class=\"sortable\">\n<thead>\n<tr>")
+	for _, c := range t.Columns {
+		fmt.Fprintf(b, "<th%s>%s</th>", alignClass(c.Align), esc(c.Title))
+	}
+	b.WriteString("</tr>\n</thead>\n<tbody>\n")
+	for _, row := range t.Rows {
+		if row.Anchor != "" {
+			fmt.Fprintf(b, "<tr id=\"%s\">", esc(row.Anchor))
+		} else {
+			b.WriteString("<tr>")
+		}
+		for i, cell := range row.Cells {
+			content := joinText(cell, h.text)
+			if i == 0 && row.Anchor != "" {
+				content += fmt.Sprintf("<a class=\"anchor\" href=\"#%s\">#</a>", esc(row.Anchor))
+			}
+			var align Alignment
+			if i < len(t.Columns) {
+				align = t.Columns[i].Align
+			}
+			fmt.Fprintf(b, "<td%s>%s</td>", alignClass(align), content)
+		}
+		b.WriteString("</tr>\n")
+	}
+	b.WriteString("</tbody>\n</table>\n")
+}
+
+func alignClass(a Alignment) string {
+	if a == AlignCenter {
+		return ` class="center"`
+	}
+	return ""
+}
+
+func (htmlMarkup) code(b *bytes.Buffer, c CodeBlock) {
+	if c.Lang != "" {
+		fmt.Fprintf(b, "<pre><code class=\"language-%s\">%s</code></pre>\n", esc(c.Lang), esc(c.Code))
+		return
+	}
+	fmt.Fprintf(b, "<pre><code>%s</code></pre>\n", esc(c.Code))
+}
+
+// diagram emits the Mermaid source; pages that load Mermaid render it.
+func (htmlMarkup) diagram(b *bytes.Buffer, d *Diagram) {
+	fmt.Fprintf(b, "<pre class=\"mermaid\">%s</pre>\n", esc(d.Mermaid()))
+}
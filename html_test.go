@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestHTMLText(t *testing.T) {
+	long := strings.Repeat("x", htmlCollapseLen+1)
+	tests := []struct {
+		text Text
+		want string
+	}{
+		{Text{Value: "a < b & c"}, "a &lt; b &amp; c"},
+		{Text{Value: "one\ntwo"}, "one<br>two"},
+		{Text{Value: "bold", Strong: true}, "<strong>bold</strong>"},
+		{Text{Value: "old", Strike: true}, "<del>old</del>"},
+		{Text{Value: `"x"`, Code: true}, "<code>&#34;x&#34;</code>"},
+		{Text{Value: "docs", Link: "https://example.com/?a=1&b=2"}, `<a href="https://example.com/?a=1&amp;b=2">docs</a>`},
+		{Text{Value: "{\n  a = 1\n}", Code: true}, "<details><summary><code>{…</code></summary><pre><code>{\n  a = 1\n}</code></pre></details>"},
+		{Text{Value: long, Code: true}, "<details><summary><code>" + long[:htmlCollapseLen] + "…</code></summary><pre><code>" + long + "</code></pre></details>"},
+	}
+	for _, tt := range tests {
+		if got := (htmlMarkup{}).text(tt.text); got != tt.want {
+			t.Errorf("text(%+v) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestHTMLRender(t *testing.T) {
+	var b bytes.Buffer
+	if err := (markupRenderer{htmlMarkup{}}).Render(&b, sampleDocument()); err != nil {
+		t.Fatal(err)
+	}
+	want := `<h1>my_*module*</h1>
+<h2 id="usage-notes">` + "`Usage`" + ` *notes*<a class="anchor" href="#usage-notes">#</a></h2>
+<ul>
+<li><a href="#-launch">🚀 Launch</a>
+</li>
+<li><a href="#input">Input</a>
+</li>
+</ul>
+<h2 id="-launch">🚀 Launch<a class="anchor" href="#-launch">#</a></h2>
+<p>Run <code>terraform apply</code>.</p>
+<h2 id="input">Input<a class="anchor" href="#input">#</a></h2>
+<table class="sortable">
+<thead>
+<tr><th>Name</th><th class="center">Required</th></tr>
+</thead>
+<tbody>
+<tr id="input-region"><td><code>region</code><a class="anchor" href="#input-region">#</a></td><td class="center">yes</td></tr>
+<tr><td>a|b</td><td class="center"><details><summary><code>{…</code></summary><pre><code>{
+  a = 1
+}</code></pre></details></td></tr>
+</tbody>
+</table>
+<h3 id="2024-notes">2024 notes<a class="anchor" href="#2024-notes">#</a></h3>
+<ul>
+<li><strong>Strong</strong></li>
+<li><a href="https://example.com">docs</a></li>
+</ul>
+<pre><code class="language-hcl">x = 1</code></pre>
+`
+	if got := b.String(); got != want {
+		t.Errorf("html body = %s, want %s", got, want)
+	}
+}
+
+func TestHTMLPage(t *testing.T) {
+	var b bytes.Buffer
+	doc := &Document{Title: "a <b> module", Sections: []*Section{{Title: "Input"}}}
+	if err := renderers["html"].Render(&b, doc); err != nil {
+		t.Fatal(err)
+	}
+	page := b.String()
+	for _, want := range []string{
+		"<title>a &lt;b&gt; module</title>",
+		"<h1>a &lt;b&gt; module</h1>",
+		`<h2 id="input">Input<a class="anchor" href="#input">#</a></h2>`,
+		"<script>",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page does not contain %q", want)
+		}
+	}
+}
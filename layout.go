@@ -0,0 +1,114 @@
+package main
+
+import "strings"
+
+// Layouts of the inputs.
+const (
+	layoutTable      = "table"
+	layoutSplitTable = "split-table"
+	layoutDocument   = "document"
+	layoutList       = "list"
+)
+
+// layouts are the valid values of the -layout flag.
+var layouts = []string{layoutTable, layoutSplitTable, layoutDocument, layoutList}
+
+// layoutInputs renders inputs in a layout. The rows of table hold the
+// cells of the inputs, in the same order. The document layout also serves
+// outputs.
+func layoutInputs(layout string, table *Table, inputs []HCLVar) []Block {
+	switch layout {
+	case layoutDocument:
+		blocks := make([]Block, 0, len(inputs))
+		for i, v := range inputs {
+			blocks = append(blocks, inputEntry(v, table.Columns, table.Rows[i]))
+		}
+		return blocks
+	case layoutList:
+		items := make(List, 0, len(inputs))
+		for i, v := range inputs {
+			items = append(items, inputItem(v, table.Rows[i]))
+		}
+		return []Block{items}
+	}
+	return []Block{table}
+}
+
+// isCodeBlock reports whether a cell is code spanning several lines, which
+// reads best as a code block.
+func isCodeBlock(c Cell) bool {
+	return len(c) == 1 && c[0].Code && strings.Contains(c[0].Value, "\n")
+}
+
+// inputEntry returns the section documenting an input in full: its
+// description, then the other cells of its row as a list, with multi-line
+// values and validations following the list.
+func inputEntry(v HCLVar, columns []Column, row Row) *Section {
+	s := &Section{Title: v.Name}
+	if desc := Paragraph(row.Cells[1]); len(desc) > 0 {
+		s.Blocks = append(s.Blocks, desc)
+	}
+	if v.Deprecated {
+		p := Paragraph{{Value: "Deprecated", Strong: true}}
+		if v.Replacement != "" {
+			p = append(p, Text{Value: ", use "}, Text{Value: v.Replacement, Code: true})
+		}
+		s.Blocks = append(s.Blocks, p)
+	}
+
+	var props List
+	var blocks []Block
+	if link := row.Cells[0][0].Link; link != "" {
+		props = append(props, Paragraph{{Value: "Source: "}, {Value: link, Link: link}})
+	}
+	for i, c := range row.Cells[2:] {
+		title := columns[i+2].Title
+		switch {
+		case len(c) == 0:
+		case isCodeBlock(c):
+			blocks = append(blocks, Paragraph{{Value: title + ":"}}, CodeBlock{Lang: "hcl", Code: c[0].Value})
+		default:
+			props = append(props, append(Paragraph{{Value: title + ": "}}, c...))
+		}
+	}
+	if len(props) > 0 {
+		s.Blocks = append(s.Blocks, props)
+	}
+	s.Blocks = append(s.Blocks, blocks...)
+
+	if len(v.Validations) > 0 {
+		var rules List
+		for _, val := range v.Validations {
+			rules = append(rules, append(Paragraph{{Value: unwrapExpr(val.Condition), Code: true}, {Value: ": "}}, codeSpans(val.ErrorMessage)...))
+		}
+		s.Blocks = append(s.Blocks, Paragraph{{Value: "Validations:"}}, rules)
+	}
+	return s
+}
+
+// inputItem returns the compact list item of an input: its name, then
+// its type, whether it is required and its default in parentheses, then its
+// description. Values spanning several lines are left out.
+func inputItem(v HCLVar, row Row) Paragraph {
+	p := Paragraph(append(Cell{}, row.Cells[0]...))
+	p = append(p, Text{Value: " ("})
+	if typ := row.Cells[2]; len(typ) > 0 && !isCodeBlock(typ) {
+		p = append(p, typ...)
+		p = append(p, Text{Value: ", "})
+	}
+	if v.Required {
+		p = append(p, Text{Value: "required"})
+	} else {
+		p = append(p, Text{Value: "optional"})
+	}
+	if def := row.Cells[3]; len(def) > 0 && !isCodeBlock(def) {
+		p = append(p, Text{Value: ", default "})
+		p = append(p, def...)
+	}
+	p = append(p, Text{Value: ")"})
+	if desc := row.Cells[1]; len(desc) > 0 {
+		p = append(p, Text{Value: ": "})
+		p = append(p, desc...)
+	}
+	return p
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestIsCodeBlock(t *testing.T) {
+	tests := []struct {
+		cell Cell
+		want bool
+	}{
+		{nil, false},
+		{plain("a\nb"), false},
+		{code(`"x"`), false},
+		{code("{\n  a = 1\n}"), true},
+		{Cell{{Value: "{\n", Code: true}, {Value: "}", Code: true}}, false},
+	}
+	for _, tt := range tests {
+		if got := isCodeBlock(tt.cell); got != tt.want {
+			t.Errorf("isCodeBlock(%+v) = %t, want %t", tt.cell, got, tt.want)
+		}
+	}
+}
+
+func TestLayoutInputs(t *testing.T) {
+	m := &Module{Inputs: []HCLVar{
+		{Name: "region", Description: "AWS region.", VarType: "string", Required: true, Nullable: true},
+		{
+			Name:        "tags",
+			VarType:     "map(string)",
+			Default:     map[string]interface{}{"a": "b", "c": "d"},
+			DefaultVal:  "{\n  a = \"b\"\n  c = \"d\"\n}",
+			Nullable:    true,
+			Deprecated:  true,
+			Replacement: "labels",
+			Validations: []Validation{{Condition: "length(var.tags) < 10", ErrorMessage: "Too many `tags`."}},
+		},
+	}}
+	tests := []struct {
+		layout, want string
+	}{
+		{layoutList, `
+## Input
+
+- region (` + "`string`" + `, required): AWS region.
+- ~~tags~~ **deprecated**, use ` + "`labels`" + ` (` + "`map(string)`" + `, optional)
+`},
+		{layoutDocument, `
+## Input
+
+### region
+
+AWS region.
+
+- Type: ` + "`string`" + `
+- Required: yes
+- Sensitive: no
+- Nullable: yes
+
+### tags
+
+**Deprecated**, use ` + "`labels`" + `
+
+- Type: ` + "`map(string)`" + `
+- Required: no
+- Sensitive: no
+- Nullable: yes
+
+Default:
+
+` + "```hcl" + `
+{
+  a = "b"
+  c = "d"
+}
+` + "```" + `
+
+Validations:
+
+- ` + "`length(var.tags) < 10`" + `: Too many ` + "`tags`" + `.
+`},
+		{layoutSplitTable, `
+## Input
+
+### Required inputs
+
+| Name   | Description |   Type   | Default | Required | Sensitive | Nullable |
+| ------ | ----------- | :------: | :-----: | :------: | :-------: | :------: |
+| region | AWS region. | ` + "`string`" + ` |         |   yes    |    no     |   yes    |
+
+### Optional inputs
+
+| Name                                  | Description |     Type      |        Default         | Required | Sensitive | Nullable |
+| ------------------------------------- | ----------- | :-----------: | :--------------------: | :------: | :-------: | :------: |
+| ~~tags~~ **deprecated**, use ` + "`labels`" + ` |             | ` + "`map(string)`" + ` | ` + "`{ a = \"b\", c = \"d\" }`" + ` |    no    |    no     |   yes    |
+`},
+	}
+	for _, tt := range tests {
+		doc := newDocument("Test", m, docOptions{Layout: tt.layout})
+		var input *Section
+		for _, s := range doc.Sections {
+			if s.Title == "Input" {
+				input = s
+			}
+		}
+		if input == nil {
+			t.Fatalf("%s: no Input section", tt.layout)
+		}
+		var b bytes.Buffer
+		if err := renderers["markdown"].Render(&b, &Document{Sections: []*Section{input}}); err != nil {
+			t.Fatal(err)
+		}
+		if got := b.String(); got != tt.want {
+			t.Errorf("%s layout = %s, want %s", tt.layout, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/pkg/errors"
+)
+
+// lintContext is the module being linted and the local modules it calls.
+type lintContext struct {
+	Dir    string
+	Module *Module
+	// Called maps the names of module calls with a local source to the
+	// called module.
+	Called map[string]*Module
+}
+
+// issue returns an issue at a position of the module.
+func (c *lintContext) issue(rule string, pos hcl.Range, format string, args ...interface{}) Issue {
+	return Issue{
+		Path: filepath.Join(c.Dir, pos.Filename),
+		Pos:  pos,
+		Rule: rule,
+		Msg:  fmt.Sprintf(format, args...),
+	}
+}
+
+// lintRules are the checks of the lint command, in reporting order for
+// issues at the same position.
+var lintRules = []func(c *lintContext) []Issue{
+	checkReferences,
+	checkOutputs,
+	checkSensitiveOutputs,
+	checkCredentialDefaults,
+}
+
+// isLocalSource reports whether a module source is a local path.
+func isLocalSource(source string) bool {
+	return strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../")
+}
+
+// checkReferences reports references to undeclared variables, locals and
+// modules, and to outputs that a called local module does not have.
+func checkReferences(c *lintContext) []Issue {
+	declared := map[string]bool{}
+	for _, v := range c.Module.Inputs {
+		declared["var."+v.Name] = true
+	}
+	rs := referrers(c.Module)
+	for _, r := range rs {
+		declared[r.Address] = true
+	}
+
+	var issues []Issue
+	for _, r := range rs {
+		for _, ref := range r.Refs {
+			subject := ref.Subject()
+			switch {
+			case strings.HasPrefix(subject, "var.") && !declared[subject]:
+				issues = append(issues, c.issue("undefined-variable", ref.Pos, "reference to undeclared input variable %q", strings.TrimPrefix(subject, "var.")))
+			case strings.HasPrefix(subject, "local.") && !declared[subject]:
+				issues = append(issues, c.issue("undefined-local", ref.Pos, "reference to undefined local value %q", strings.TrimPrefix(subject, "local.")))
+			case strings.HasPrefix(subject, "module.") && !declared[subject]:
+				issues = append(issues, c.issue("undefined-module", ref.Pos, "reference to undeclared module call %q", strings.TrimPrefix(subject, "module.")))
+			case strings.HasPrefix(subject, "module."):
+				parts := splitTraversal(ref.Traversal)
+				called, ok := c.Called[parts[1]]
+				if !ok || len(parts) < 3 {
+					continue
+				}
+				if !hasOutput(called, parts[2]) {
+					issues = append(issues, c.issue("undefined-module-output", ref.Pos, "module %q has no output %q", parts[1], parts[2]))
+				}
+			}
+		}
+	}
+	return issues
+}
+
+func hasOutput(m *Module, name string) bool {
+	for _, o := range m.Outputs {
+		if o.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// checkOutputs reports outputs whose value references resources or data
+// sources that are not declared.
+func checkOutputs(c *lintContext) []Issue {
+	declared := map[string]bool{}
+	for _, r := range c.Module.Resources {
+		declared[r.Address()] = true
+	}
+
+	var issues []Issue
+	for _, o := range c.Module.Outputs {
+		for _, ref := range o.Refs {
+			subject := ref.Subject()
+			switch root := splitTraversal(subject)[0]; {
+			case subject == "", declared[subject]:
+			case root == "var", root == "local", root == "module":
+			case root != "data" && root != "ephemeral" && !strings.Contains(root, "_"):
+				// Resource types are prefixed with their provider name,
+				// e.g. aws_vpc; other names are bound by the expression.
+			default:
+				issues = append(issues, c.issue("dead-output", ref.Pos, "output %q references %s, which is not declared", o.Name, subject))
+			}
+		}
+	}
+	return issues
+}
+
+// lintModule runs all lint rules over the module in dir. Issues are
+// ordered by file, line and column.
+func lintModule(dir string, m *Module) ([]Issue, error) {
+	c := &lintContext{Dir: dir, Module: m, Called: map[string]*Module{}}
+	for _, call := range m.Calls {
+		if !isLocalSource(call.Source) {
+			continue
+		}
+		called, err := loadModule(dirSource(filepath.Join(dir, call.Source)))
+		if err != nil {
+			return nil, errors.Wrapf(err, "module %q", call.Name)
+		}
+		c.Called[call.Name] = called
+	}
+
+	var issues []Issue
+	for _, rule := range lintRules {
+		issues = append(issues, rule(c)...)
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Pos.Start.Line != b.Pos.Start.Line {
+			return a.Pos.Start.Line < b.Pos.Start.Line
+		}
+		return a.Pos.Start.Column < b.Pos.Start.Column
+	})
+	return issues, nil
+}
+
+// runLint implements the lint command.
+func runLint(args []string) {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: tfreadme lint [module-dir]\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+
+	m, err := loadModule(dirSource(dir))
+	if err != nil {
+		log.Fatalf("Error loading module %s: %s.", dir, err)
+	}
+	issues, err := lintModule(dir, m)
+	if err != nil {
+		log.Fatalf("Error loading called module: %s.", err)
+	}
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+	if len(issues) > 0 {
+		os.Exit(1)
+	}
+}
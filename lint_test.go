@@ -0,0 +1,102 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLintModule(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{
+			name: "clean",
+			files: map[string]string{
+				"main.tf": `
+variable "cidr" {}
+locals { name = "vpc" }
+resource "aws_vpc" "this" {
+  cidr_block = var.cidr
+  tags       = { Name = local.name }
+}
+module "subnets" {
+  source = "./subnets"
+  vpc_id = aws_vpc.this.id
+}
+output "subnet_ids" { value = module.subnets.ids }
+`,
+				"subnets/main.tf": `
+variable "vpc_id" {}
+output "ids" { value = [] }
+`,
+			},
+		},
+		{
+			name: "undefined references in position order",
+			files: map[string]string{
+				"a.tf": `
+output "id" {
+  value = aws_vpc.missing.id
+}
+output "sg" {
+  value = module.sg.id
+}
+`,
+				"b.tf": `
+resource "aws_vpc" "this" {
+  tags       = { Name = local.name }
+  cidr_block = var.cidr
+}
+`,
+			},
+			want: []string{
+				`a.tf:3:11: output "id" references aws_vpc.missing, which is not declared (dead-output)`,
+				`a.tf:6:11: reference to undeclared module call "sg" (undefined-module)`,
+				`b.tf:3:25: reference to undefined local value "name" (undefined-local)`,
+				`b.tf:4:16: reference to undeclared input variable "cidr" (undefined-variable)`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		dir := writeModule(t, tt.files)
+		m, err := loadModule(dirSource(dir))
+		if err != nil {
+			t.Fatalf("%s: loadModule: %s", tt.name, err)
+		}
+		issues, err := lintModule(dir, m)
+		if err != nil {
+			t.Fatalf("%s: lintModule: %s", tt.name, err)
+		}
+		var got []string
+		for _, issue := range issues {
+			rel, _ := filepath.Rel(dir, issue.Path)
+			issue.Path = rel
+			got = append(got, issue.String())
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: issues = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// writeModule writes the files of a module to a temporary directory and
+// returns the directory.
+func writeModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
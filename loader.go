@@ -0,0 +1,293 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Loader reads the interface of a module.
+type Loader interface {
+	Load() (*Module, error)
+	// String describes the loader's input for error messages.
+	String() string
+}
+
+// hclLoader parses the configuration files of a module.
+type hclLoader struct {
+	Source fileSource
+}
+
+// Load implements Loader.
+func (l hclLoader) Load() (*Module, error) {
+	return loadModule(l.Source)
+}
+
+func (l hclLoader) String() string {
+	return l.Source.String()
+}
+
+// planLoader reads the configuration section of the JSON written by
+// `terraform show -json`, for a plan file or a configuration.
+type planLoader string
+
+// planExpression is an expression of the configuration. Only the
+// references of expressions are used.
+type planExpression struct {
+	References []string `json:"references"`
+}
+
+// planConfig is the configuration section of a plan.
+type planConfig struct {
+	ProviderConfig map[string]struct {
+		Name              string                     `json:"name"`
+		FullName          string                     `json:"full_name"`
+		Alias             string                     `json:"alias"`
+		VersionConstraint string                     `json:"version_constraint"`
+		ModuleAddress     string                     `json:"module_address"`
+		Expressions       map[string]json.RawMessage `json:"expressions"`
+	} `json:"provider_config"`
+	RootModule planModule `json:"root_module"`
+}
+
+// planModule is the configuration of a module.
+type planModule struct {
+	Variables map[string]struct {
+		Default     json.RawMessage `json:"default"`
+		Description string          `json:"description"`
+		Sensitive   bool            `json:"sensitive"`
+		Ephemeral   bool            `json:"ephemeral"`
+	} `json:"variables"`
+	Outputs map[string]struct {
+		Expression  planExpression `json:"expression"`
+		Description string         `json:"description"`
+		Sensitive   bool           `json:"sensitive"`
+		Ephemeral   bool           `json:"ephemeral"`
+		DependsOn   []string       `json:"depends_on"`
+	} `json:"outputs"`
+	Resources []struct {
+		Mode        string                     `json:"mode"`
+		Type        string                     `json:"type"`
+		Name        string                     `json:"name"`
+		Expressions map[string]json.RawMessage `json:"expressions"`
+	} `json:"resources"`
+	ModuleCalls map[string]struct {
+		Source            string                     `json:"source"`
+		VersionConstraint string                     `json:"version_constraint"`
+		Expressions       map[string]json.RawMessage `json:"expressions"`
+		Module            planModule                 `json:"module"`
+	} `json:"module_calls"`
+}
+
+// Load implements Loader.
+func (l planLoader) Load() (*Module, error) {
+	raw, err := ioutil.ReadFile(string(l))
+	if err != nil {
+		return nil, errors.Wrap(err, "read file")
+	}
+	var plan struct {
+		Configuration *planConfig `json:"configuration"`
+	}
+	if err := json.Unmarshal(raw, &plan); err != nil {
+		return nil, errors.Wrap(err, "parse")
+	}
+	if plan.Configuration == nil {
+		return nil, errors.New("no configuration section, expected the output of terraform show -json")
+	}
+
+	m := plan.Configuration.RootModule.module()
+	keys := make([]string, 0, len(plan.Configuration.ProviderConfig))
+	for key := range plan.Configuration.ProviderConfig {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		p := plan.Configuration.ProviderConfig[key]
+		if p.ModuleAddress != "" {
+			continue
+		}
+		m.addProvider(ProviderRequirement{
+			Name:    p.Name,
+			Source:  strings.TrimPrefix(p.FullName, "registry.terraform.io/"),
+			Version: p.VersionConstraint,
+		})
+		m.ProviderConfigs = append(m.ProviderConfigs, ProviderConfig{
+			Name:  p.Name,
+			Alias: p.Alias,
+			Refs:  expressionRefs(p.Expressions),
+		})
+	}
+	return m, nil
+}
+
+func (l planLoader) String() string {
+	return string(l)
+}
+
+// sortedKeys returns the keys of a JSON object in order.
+func sortedKeys(obj interface{}) []string {
+	var keys []string
+	switch obj := obj.(type) {
+	case map[string]json.RawMessage:
+		for k := range obj {
+			keys = append(keys, k)
+		}
+	case map[string]interface{}:
+		for k := range obj {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// module converts the configuration of a module. The JSON has no source
+// positions and omits type constraints, nullability, validations and
+// locals, so these stay empty.
+func (pm planModule) module() *Module {
+	m := &Module{NullableUnknown: true}
+
+	var names []string
+	for name := range pm.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		v := pm.Variables[name]
+		hclVar := HCLVar{
+			Name:        name,
+			Description: v.Description,
+			Required:    len(v.Default) == 0,
+			Sensitive:   v.Sensitive,
+			Ephemeral:   v.Ephemeral,
+		}
+		// An explicit null default is kept as raw "null", so only an
+		// absent key makes the variable required.
+		if len(v.Default) > 0 {
+			var def interface{}
+			if err := json.Unmarshal(v.Default, &def); err == nil {
+				hclVar.Default = jsonValue(def)
+				hclVar.DefaultVal = formatValue(hclVar.Default)
+			}
+		}
+		hclVar.Deprecated, hclVar.Replacement = deprecation(nil, v.Description)
+		m.Inputs = append(m.Inputs, hclVar)
+	}
+
+	names = names[:0]
+	for name := range pm.Outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		o := pm.Outputs[name]
+		hclVar := HCLVar{
+			Name:        name,
+			Description: o.Description,
+			Sensitive:   o.Sensitive,
+			Ephemeral:   o.Ephemeral,
+			DependsOn:   o.DependsOn,
+			Refs:        append(planRefs("value", o.Expression.References), planRefs("depends_on", o.DependsOn)...),
+		}
+		hclVar.Deprecated, hclVar.Replacement = deprecation(nil, o.Description)
+		m.Outputs = append(m.Outputs, hclVar)
+	}
+
+	for _, r := range pm.Resources {
+		m.Resources = append(m.Resources, Resource{
+			Mode: r.Mode,
+			Type: r.Type,
+			Name: r.Name,
+			Refs: expressionRefs(r.Expressions),
+		})
+	}
+
+	names = names[:0]
+	for name := range pm.ModuleCalls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		call := pm.ModuleCalls[name]
+		m.Calls = append(m.Calls, ModuleCall{
+			Name:    name,
+			Source:  call.Source,
+			Version: call.VersionConstraint,
+			Refs:    expressionRefs(call.Expressions),
+			Module:  call.Module.module(),
+		})
+	}
+	return m
+}
+
+// jsonValue converts a decoded JSON value to the values of ctyValue, whose
+// numbers are int64 when integral.
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case float64:
+		if v == float64(int64(v)) {
+			return int64(v)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = jsonValue(e)
+		}
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = jsonValue(e)
+		}
+	}
+	return v
+}
+
+// planRefs converts the references of an expression of the given attribute.
+func planRefs(attr string, refs []string) []Reference {
+	out := make([]Reference, 0, len(refs))
+	for _, r := range refs {
+		out = append(out, Reference{Traversal: r, Attr: attr})
+	}
+	return out
+}
+
+// expressionRefs returns the references of the expressions of a block,
+// including those of its nested blocks, which are arrays of expression
+// objects. References are attributed to the top-level attribute.
+func expressionRefs(exprs map[string]json.RawMessage) []Reference {
+	var refs []Reference
+	for _, attr := range sortedKeys(exprs) {
+		var v interface{}
+		if err := json.Unmarshal(exprs[attr], &v); err != nil {
+			continue
+		}
+		refs = append(refs, planRefs(attr, collectReferences(v))...)
+	}
+	return refs
+}
+
+// collectReferences returns the references of all the expressions in a
+// decoded expressions value.
+func collectReferences(v interface{}) []string {
+	var refs []string
+	switch v := v.(type) {
+	case []interface{}:
+		for _, e := range v {
+			refs = append(refs, collectReferences(e)...)
+		}
+	case map[string]interface{}:
+		if list, ok := v["references"].([]interface{}); ok {
+			for _, r := range list {
+				if s, ok := r.(string); ok {
+					refs = append(refs, s)
+				}
+			}
+			return refs
+		}
+		for _, k := range sortedKeys(v) {
+			refs = append(refs, collectReferences(v[k])...)
+		}
+	}
+	return refs
+}
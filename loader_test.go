@@ -0,0 +1,108 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPlanLoaderVariables(t *testing.T) {
+	tests := []struct {
+		name     string
+		variable string
+		required bool
+		def      interface{}
+		defVal   string
+	}{
+		{"absent default", `{"description": "Region."}`, true, nil, ""},
+		{"null default", `{"default": null}`, false, nil, "null"},
+		{"string default", `{"default": "eu-west-1"}`, false, "eu-west-1", `"eu-west-1"`},
+		{"number default", `{"default": 3}`, false, int64(3), "3"},
+		{"list default", `{"default": [1, 2]}`, false, []interface{}{int64(1), int64(2)}, "[1, 2]"},
+	}
+	for _, tt := range tests {
+		m := loadPlan(t, `{"configuration": {"root_module": {"variables": {"v": `+tt.variable+`}}}}`)
+		if len(m.Inputs) != 1 {
+			t.Fatalf("%s: got %d inputs, want 1", tt.name, len(m.Inputs))
+		}
+		in := m.Inputs[0]
+		if in.Required != tt.required {
+			t.Errorf("%s: Required = %t, want %t", tt.name, in.Required, tt.required)
+		}
+		if !reflect.DeepEqual(in.Default, tt.def) {
+			t.Errorf("%s: Default = %#v, want %#v", tt.name, in.Default, tt.def)
+		}
+		if in.DefaultVal != tt.defVal {
+			t.Errorf("%s: DefaultVal = %q, want %q", tt.name, in.DefaultVal, tt.defVal)
+		}
+	}
+}
+
+func TestPlanLoaderModuleCalls(t *testing.T) {
+	m := loadPlan(t, `{"configuration": {"root_module": {
+		"module_calls": {
+			"vpc": {
+				"source": "terraform-aws-modules/vpc/aws",
+				"version_constraint": "~> 5.0",
+				"expressions": {"cidr": {"references": ["var.cidr"]}},
+				"module": {
+					"variables": {"cidr": {}, "tags": {"default": null}},
+					"module_calls": {
+						"subnets": {
+							"source": "./subnets",
+							"module": {"outputs": {"ids": {"expression": {"references": ["aws_subnet.this"]}}}}
+						}
+					}
+				}
+			}
+		}
+	}}}`)
+
+	if len(m.Calls) != 1 {
+		t.Fatalf("got %d module calls, want 1", len(m.Calls))
+	}
+	vpc := m.Calls[0]
+	if vpc.Name != "vpc" || vpc.Source != "terraform-aws-modules/vpc/aws" || vpc.Version != "~> 5.0" {
+		t.Errorf("call = %s %s %s, want vpc terraform-aws-modules/vpc/aws ~> 5.0", vpc.Name, vpc.Source, vpc.Version)
+	}
+	if want := []Reference{{Traversal: "var.cidr", Attr: "cidr"}}; !reflect.DeepEqual(vpc.Refs, want) {
+		t.Errorf("vpc refs = %v, want %v", vpc.Refs, want)
+	}
+	var required []string
+	for _, in := range vpc.Module.Inputs {
+		if in.Required {
+			required = append(required, in.Name)
+		}
+	}
+	if want := []string{"cidr"}; !reflect.DeepEqual(required, want) {
+		t.Errorf("vpc required inputs = %v, want %v", required, want)
+	}
+	if len(vpc.Module.Calls) != 1 {
+		t.Fatalf("got %d nested module calls, want 1", len(vpc.Module.Calls))
+	}
+	subnets := vpc.Module.Calls[0]
+	if subnets.Name != "subnets" || subnets.Source != "./subnets" {
+		t.Errorf("nested call = %s %s, want subnets ./subnets", subnets.Name, subnets.Source)
+	}
+	if len(subnets.Module.Outputs) != 1 {
+		t.Fatalf("got %d nested outputs, want 1", len(subnets.Module.Outputs))
+	}
+	if want := []Reference{{Traversal: "aws_subnet.this", Attr: "value"}}; !reflect.DeepEqual(subnets.Module.Outputs[0].Refs, want) {
+		t.Errorf("nested output refs = %v, want %v", subnets.Module.Outputs[0].Refs, want)
+	}
+}
+
+// loadPlan loads the module of the given plan JSON.
+func loadPlan(t *testing.T, plan string) *Module {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := ioutil.WriteFile(path, []byte(plan), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := planLoader(path).Load()
+	if err != nil {
+		t.Fatalf("Load: %s", err)
+	}
+	return m
+}
@@ -3,15 +3,12 @@ package main
 import (
 	"flag"
 	"fmt"
-	"html/template"
-	"io"
-	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
 
-	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/v2"
 	"github.com/pkg/errors"
 )
 
@@ -20,132 +17,234 @@ type HCLVar struct {
 	Name        string
 	Description string
 	VarType     string
-	DefaultVal  string
+	Default     interface{} // Decoded default value, nil if unset.
+	DefaultVal  string      // Default formatted as an HCL literal.
 	Required    bool
 	Sensitive   bool
+	Nullable    bool
+	Ephemeral   bool
+	Deprecated  bool
+	Replacement string // Name of the replacement of a deprecated block.
+	Group       string // Group of a variable, from a "# @group" comment.
+	Validations []Validation
+	// Preconditions are the precondition blocks of an output, with the
+	// same fields as a validation.
+	Preconditions []Validation
+	DependsOn     []string    // Explicit dependencies of an output.
+	Value         string      // Value expression of an output, as written.
+	Refs          []Reference // References of an output, or of the validations of a variable.
+	Pos           hcl.Range   // Range of the block header, with its file name.
 }
 
-func printTitle(w io.Writer) error {
-	// Construct the title header.
-	wd, err := os.Getwd()
+// Validation is a custom validation rule of a variable.
+type Validation struct {
+	Condition    string
+	ErrorMessage string
+}
+
+// moduleTitle constructs the title header of the module in dir.
+func moduleTitle(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
 	if err != nil {
-		return errors.Wrap(err, "Getwd")
+		return "", errors.Wrap(err, "Abs")
 	}
-	_, err = fmt.Fprintf(w, "\n# %s Terraform Module\n", strings.ToTitle(filepath.Base(wd)))
-	return errors.Wrap(err, "write stream")
+	return fmt.Sprintf("%s Terraform Module", strings.ToTitle(filepath.Base(abs))), nil
 }
 
-func hclTable(vars []map[string]interface{}) []HCLVar {
-	hclVars := make([]HCLVar, 0, len(vars))
+// loadBlocks reads an HCL file and returns the table of its top-level blocks
+// of the given kind, e.g. "variable" or "output".
+func loadBlocks(path, kind string) ([]HCLVar, error) {
+	f, err := parseHCLFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeVars(f, kind)
+}
 
-	for _, varmap := range vars {
-		for name, v := range varmap {
-			for _, x := range v.([]map[string]interface{}) {
-				var hclVar HCLVar
+// commands maps subcommand names to their entry points. Without a
+// subcommand, tfreadme generates the README.
+var commands = map[string]func(args []string){
+	"tfvars": runTfvars,
+	"schema": runSchema,
 
-				hclVar.Name = name
-				hclVar.Description, _ = x["description"].(string)
-				hclVar.VarType, _ = x["type"].(string)
-				hclVar.DefaultVal, _ = x["default"].(string)
-				hclVar.Required = hclVar.DefaultVal == ""
-				hclVar.Sensitive, _ = x["sensitive"].(bool)
+	"validate-tfvars": runValidateTfvars,
+	"diff":            runDiff,
+	"changelog":       runChangelog,
+	"lint":            runLint,
+}
 
-				hclVars = append(hclVars, hclVar)
-			}
+func main() {
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			cmd(os.Args[2:])
+			return
 		}
 	}
 
-	return hclVars
-}
-
-func main() {
 	var (
 		verbose       = flag.Bool("v", false, "verbose mode")
-		variablesFile = flag.String("variables", "variables.tf", "path to variables file")
-		outputsFile   = flag.String("outputs", "outputs.tf", "path to outputs file")
+		variablesFile = flag.String("variables", "", "path to variables file, instead of reading the module directory")
+		outputsFile   = flag.String("outputs", "", "path to outputs file, instead of reading the module directory")
+		format        = flag.String("format", "markdown", "output format: "+strings.Join(formatNames(), ", "))
+		ref           = flag.String("ref", "", "git revision to document, read from the object database instead of the working tree")
+		diagram       = flag.Bool("diagram", false, "add an Architecture section with a diagram of the module's resources")
+		usedBy        = flag.Bool("used-by", false, "add a column listing where each input is referenced")
+		planFile      = flag.String("plan", "", "path to the JSON of terraform show -json, to document the configuration Terraform evaluated instead of parsing the module")
+		stateFile     = flag.String("state", "", "path to the JSON of terraform show -json for a state, to add the deployed output values and resource counts")
+		envDir        = flag.String("environments", "", "`dir`ectory of tfvars files, one per environment, to add a table of the input values of each environment")
+		sourceLinks   = flag.Bool("source-links", false, "link the names of inputs and outputs to their declarations, relative to the module directory")
+		sourceURL     = flag.String("source-url", "", "link the names of inputs and outputs to their declarations through a URL `template` with {ref}, {path} and {line}, e.g. https://github.com/org/repo/blob/{ref}/{path}#L{line}")
+		groupBy       = flag.String("group", "", "split the Input table into groups of inputs by "+strings.Join(groupings, ", ")+"; required inputs come first")
+		layout        = flag.String("layout", layoutTable, "layout of the inputs: "+strings.Join(layouts, ", "))
+		toc           = flag.Bool("toc", false, "add a table of contents at the top")
+		schemaFile    = flag.String("provider-schema", "", "path to the `file` written by terraform providers schema -json, to describe resources and infer output types")
 	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: tfreadme [flags] [module-dir]\n")
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
-	w := os.Stdout
-	if err := printTitle(w); err != nil {
-		log.Fatalf("Error printing title: %s.", err)
+	renderer, ok := renderers[*format]
+	if !ok {
+		log.Fatalf("Unknown format %q, expected one of: %s.", *format, strings.Join(formatNames(), ", "))
 	}
 
-	// Overview.
-	if _, err := fmt.Fprintf(w, "\n## Overview\n\n"); err != nil {
-		log.Fatalf("Error printing overview: %s.", err)
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
 	}
-
-	// Handle input variables.
-	rawInputs, err := ioutil.ReadFile(*variablesFile)
+	title, err := moduleTitle(dir)
 	if err != nil {
-		log.Fatalf("Error reading variables file %q: %s.", *variablesFile, err)
+		log.Fatalf("Error building title: %s.", err)
 	}
-	var hclInput interface{}
-	if err := hcl.Unmarshal(rawInputs, &hclInput); err != nil {
-		log.Fatalf("Error unmarshalling input: %s.", err)
+
+	// Relative links resolve against the working tree, which need not
+	// match the revision documented.
+	if *sourceLinks && *ref != "" {
+		log.Fatalf("Error: -source-links cannot be combined with -ref, use -source-url to link to the revision.")
 	}
 
-	vars, ok := hclInput.(map[string]interface{})["variable"]
-	if !ok && *verbose {
+	var m *Module
+	if *variablesFile != "" || *outputsFile != "" {
+		if *ref != "" || *planFile != "" {
+			log.Fatalf("Error: -ref and -plan cannot be combined with -variables or -outputs.")
+		}
+		m = loadFiles(*variablesFile, *outputsFile)
+	} else {
+		var loader Loader = hclLoader{Source: dirSource(dir)}
+		switch {
+		case *ref != "" && *planFile != "":
+			log.Fatalf("Error: -ref cannot be combined with -plan.")
+		case *ref != "":
+			loader = hclLoader{Source: gitSource{Ref: *ref, Dir: dir}}
+		case *planFile != "":
+			loader = planLoader(*planFile)
+		}
+		if m, err = loader.Load(); err != nil {
+			log.Fatalf("Error loading module %s: %s.", loader, err)
+		}
+	}
+	if len(m.Inputs) == 0 && *verbose {
 		log.Printf("No variables detected.")
 	}
+	if len(m.Outputs) == 0 && *verbose {
+		log.Printf("No outputs detected.")
+	}
 
-	hclVars := hclTable(vars.([]map[string]interface{}))
-
-	// Format and print Inputs.
-	inputTmpl, err := template.New("hclvar_input").Parse("| {{.Name}} | {{.Description}} | {{.VarType}} | {{.DefaultVal}} | {{if .Required}} yes {{else}} no {{end}} |\n")
-	if err != nil {
-		log.Fatalf("Error templating input: %s.", err)
-	}
-	// TODO: Handle errors.
-	_, _ = fmt.Fprintf(w, "\n## Input\n\n")
-	_, _ = fmt.Fprintln(w, "| Name | Description | Type | Default | Required |")
-	_, _ = fmt.Fprintln(w, "|------|-------------|:----:|:-----:|:-----:|")
-	for _, hclvar := range hclVars {
-		if err := inputTmpl.Execute(w, hclvar); err != nil {
-			log.Fatalf("Error executing input on template: %s", err)
+	if *schemaFile != "" {
+		schemas, err := readProviderSchemas(*schemaFile)
+		if err != nil {
+			log.Fatalf("Error loading provider schema %q: %s.", *schemaFile, err)
 		}
+		applyProviderSchemas(m, schemas)
 	}
 
-	// Handle outputs.
-	rawOutputs, err := ioutil.ReadFile(*outputsFile)
-	if err != nil {
-		log.Fatalf("Error reading outputs file %q: %s.", *outputsFile, err)
+	opts := docOptions{
+		Diagram:   *diagram || *format == "dot",
+		UsedBy:    *usedBy,
+		Resources: *schemaFile != "",
+	}
+	// The lock file and installed modules are those of the working tree.
+	if *variablesFile == "" && *outputsFile == "" && *ref == "" {
+		if opts.Dependencies, err = readDependencies(dir); err != nil {
+			log.Fatalf("Error loading dependencies: %s.", err)
+		}
 	}
-	var hclOut interface{}
-	if err := hcl.Unmarshal(rawOutputs, &hclOut); err != nil {
-		log.Fatalf("Error unmarshalling: %s.", err)
+	if !containsString(layouts, *layout) {
+		log.Fatalf("Unknown layout %q, expected one of: %s.", *layout, strings.Join(layouts, ", "))
 	}
-
-	outputs, ok := hclOut.(map[string]interface{})["output"]
-	if !ok && *verbose {
-		log.Printf("No outputs detected.")
+	opts.Layout = *layout
+	opts.Contents = *toc
+	if *groupBy != "" {
+		if !containsString(groupings, *groupBy) {
+			log.Fatalf("Unknown grouping %q, expected one of: %s.", *groupBy, strings.Join(groupings, ", "))
+		}
+		if *groupBy == groupByAnnotation {
+			if err := checkGroupAnnotations(m.Inputs); err != nil {
+				log.Fatalf("Error grouping inputs: %s.", err)
+			}
+		}
+		opts.GroupBy = *groupBy
 	}
-
-	// NOTE: This smells fishy.
-	hclOutputs := hclTable(outputs.([]map[string]interface{}))
-
-	// Format and print Outputs.
-	outputTmpl, err := template.New("hclvar_output").Parse("| {{.Name}} | {{.Description}} |  {{if .Sensitive}} yes {{else}} no {{end}} |\n")
-	if err != nil {
-		log.Fatalf("Error templating output: %s.", err)
-	}
-	// TODO: Handle errors.
-	_, _ = fmt.Fprintf(w, "\n## Output\n\n")
-	_, _ = fmt.Fprintln(w, "| Name | Description | Sensitive |")
-	_, _ = fmt.Fprintln(w, "|------|-------------|:----:|")
-	for _, out := range hclOutputs {
-		if err := outputTmpl.Execute(w, out); err != nil {
-			log.Fatalf("Error executing output on template: %s", err)
+	switch {
+	case *sourceURL != "":
+		if opts.SourceLinks, err = templateLinks(*sourceURL, *ref, dir); err != nil {
+			log.Fatalf("Error resolving source links: %s.", err)
+		}
+	case *sourceLinks:
+		opts.SourceLinks = relativeLinks
+	}
+	if *envDir != "" {
+		if opts.Environments, err = readEnvironments(*envDir); err != nil {
+			log.Fatalf("Error loading environments from %s: %s.", *envDir, err)
+		}
+	}
+	if *stateFile != "" {
+		if opts.State, err = readState(*stateFile); err != nil {
+			log.Fatalf("Error loading state %q: %s.", *stateFile, err)
 		}
 	}
+	doc := newDocument(title, m, opts)
+	if err := renderer.Render(os.Stdout, doc); err != nil {
+		log.Fatalf("Error rendering %s: %s.", *format, err)
+	}
+}
 
-	// TODO: Handle errors.
-	// Usage.
-	_, _ = fmt.Fprintf(w, "\n## Usage\n")
-	_, _ = fmt.Fprintf(w, "\n```\n\n```\n")
+// loadInputs returns the variables of the module in dir, or those of a
+// single variables file if one is given.
+func loadInputs(dir, variablesFile string) []HCLVar {
+	if variablesFile != "" {
+		hclVars, err := loadBlocks(variablesFile, "variable")
+		if err != nil {
+			log.Fatalf("Error loading variables file %q: %s.", variablesFile, err)
+		}
+		return hclVars
+	}
+	m, err := loadModule(dirSource(dir))
+	if err != nil {
+		log.Fatalf("Error loading module %s: %s.", dir, err)
+	}
+	return m.Inputs
+}
 
-	// Troubleshooting.
-	_, _ = fmt.Fprintf(w, "\n## Troubleshooting\n\n")
+// loadFiles builds a module from a variables file and an outputs file,
+// either of which may be empty.
+func loadFiles(variablesFile, outputsFile string) *Module {
+	m := &Module{}
+	if variablesFile != "" {
+		hclVars, err := loadBlocks(variablesFile, "variable")
+		if err != nil {
+			log.Fatalf("Error loading variables file %q: %s.", variablesFile, err)
+		}
+		m.Inputs = hclVars
+	}
+	if outputsFile != "" {
+		hclOutputs, err := loadBlocks(outputsFile, "output")
+		if err != nil {
+			log.Fatalf("Error loading outputs file %q: %s.", outputsFile, err)
+		}
+		m.Outputs = hclOutputs
+	}
+	return m
 }
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// markdown emits GitHub-flavoured Markdown.
+type markdown struct{}
+
+var (
+	mdCellEscaper = strings.NewReplacer("|", `\|`)
+	// mdTextEscaper keeps text from being read as HTML.
+	mdTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	lineBreaks    = regexp.MustCompile(`[ \t]*\r?\n\s*`)
+)
+
+func (markdown) title(b *bytes.Buffer, title string) {
+	fmt.Fprintf(b, "# %s\n", title)
+}
+
+// heading writes a heading. Its anchor is the one GitHub derives from the
+// title, so it is not written.
+func (markdown) heading(b *bytes.Buffer, level int, title, anchor string) {
+	fmt.Fprintf(b, "\n%s %s\n", strings.Repeat("#", level+1), title)
+}
+
+func (m markdown) text(t Text) string {
+	if t.Link != "" {
+		link := t.Link
+		t.Link = ""
+		return "[" + m.text(t) + "](" + link + ")"
+	}
+	if t.Strike {
+		t.Strike = false
+		return "~~" + m.text(t) + "~~"
+	}
+	if t.Strong {
+		return "**" + mdTextEscaper.Replace(t.Value) + "**"
+	}
+	if !t.Code {
+		return mdTextEscaper.Replace(t.Value)
+	}
+	if strings.Contains(t.Value, "`") {
+		return "`` " + t.Value + " ``"
+	}
+	return "`" + t.Value + "`"
+}
+
+// cellText renders a run inside a table cell. Table rows cannot break lines
+// without inline HTML, so a cell is put on one line: the line breaks
+// between the items of a list become commas, code of several lines is
+// reformatted on one line and other line breaks become spaces.
+func (m markdown) cellText(t Text) string {
+	switch {
+	case t.Value == "\n" && !t.Code:
+		return ", "
+	case t.Code:
+		t.Value = inlineCode(t.Value)
+	default:
+		t.Value = lineBreaks.ReplaceAllString(t.Value, " ")
+	}
+	return m.text(t)
+}
+
+// inlineCode puts code of several lines on one line. Values formatted by
+// formatValue are reformatted with commas between their elements; other
+// expressions have their line breaks replaced by spaces.
+func inlineCode(s string) string {
+	if !strings.Contains(s, "\n") {
+		return s
+	}
+	if v, err := parseValue(s); err == nil && formatValue(v) == s {
+		return formatInline(v)
+	}
+	return lineBreaks.ReplaceAllString(strings.TrimSpace(s), " ")
+}
+
+// trimLines removes the whitespace at the end of each line of s, which
+// markdownlint reports and Markdown would otherwise take for line breaks.
+func trimLines(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " \t")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m markdown) paragraph(b *bytes.Buffer, p Paragraph) {
+	fmt.Fprintf(b, "\n%s\n", trimLines(joinText(p, m.text)))
+}
+
+func (m markdown) list(b *bytes.Buffer, l List) {
+	b.WriteString("\n")
+	for _, item := range l {
+		fmt.Fprintf(b, "- %s\n", trimLines(joinText(item, m.text)))
+	}
+}
+
+func (m markdown) contents(b *bytes.Buffer, c Contents) {
+	b.WriteString("\n")
+	m.contentsList(b, c, "")
+}
+
+func (m markdown) contentsList(b *bytes.Buffer, c Contents, indent string) {
+	for _, e := range c {
+		fmt.Fprintf(b, "%s- %s\n", indent, m.text(Text{Value: e.Title, Link: "#" + e.Anchor}))
+		m.contentsList(b, e.Entries, indent+"  ")
+	}
+}
+
+// table writes a table with its columns padded to a common width, as
+// prettier formats tables, so that prettier leaves the output unchanged.
+// Centered columns center their content.
+func (m markdown) table(b *bytes.Buffer, t *Table) {
+	rows := make([][]string, 0, len(t.Rows)+1)
+	header := make([]string, 0, len(t.Columns))
+	for _, c := range t.Columns {
+		header = append(header, mdCellEscaper.Replace(c.Title))
+	}
+	rows = append(rows, header)
+	for _, row := range t.Rows {
+		cells := make([]string, len(t.Columns))
+		for i, cell := range row.Cells {
+			if i < len(cells) {
+				cells[i] = strings.TrimSpace(mdCellEscaper.Replace(joinText(cell, m.cellText)))
+			}
+		}
+		rows = append(rows, cells)
+	}
+
+	widths := make([]int, len(t.Columns))
+	for i := range widths {
+		// The delimiter row needs at least three characters.
+		widths[i] = 3
+		for _, cells := range rows {
+			if w := textWidth(cells[i]); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	b.WriteString("\n")
+	m.tableRow(b, t.Columns, widths, rows[0])
+	b.WriteString("|")
+	for i, c := range t.Columns {
+		if c.Align == AlignCenter {
+			fmt.Fprintf(b, " :%s: |", strings.Repeat("-", widths[i]-2))
+		} else {
+			fmt.Fprintf(b, " %s |", strings.Repeat("-", widths[i]))
+		}
+	}
+	b.WriteString("\n")
+	for _, cells := range rows[1:] {
+		m.tableRow(b, t.Columns, widths, cells)
+	}
+}
+
+// tableRow writes a row of padded cells.
+func (markdown) tableRow(b *bytes.Buffer, columns []Column, widths []int, cells []string) {
+	b.WriteString("|")
+	for i, cell := range cells {
+		pad := widths[i] - textWidth(cell)
+		before := 0
+		if columns[i].Align == AlignCenter {
+			before = pad / 2
+		}
+		fmt.Fprintf(b, " %s%s%s |", strings.Repeat(" ", before), cell, strings.Repeat(" ", pad-before))
+	}
+	b.WriteString("\n")
+}
+
+func (markdown) code(b *bytes.Buffer, c CodeBlock) {
+	fmt.Fprintf(b, "\n```%s\n%s\n```\n", c.Lang, c.Code)
+}
+
+func (m markdown) diagram(b *bytes.Buffer, d *Diagram) {
+	m.code(b, CodeBlock{Lang: "mermaid", Code: d.Mermaid()})
+}
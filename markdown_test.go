@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMarkdownTable(t *testing.T) {
+	tests := []struct {
+		name  string
+		table *Table
+		want  string
+	}{
+		{
+			name: "padding",
+			table: &Table{
+				Columns: []Column{{Title: "Name"}, {Title: "Required", Align: AlignCenter}},
+				Rows: []Row{
+					{Cells: []Cell{plain("region"), plain("yes")}},
+					{Cells: []Cell{plain("availability_zones"), plain("no")}},
+				},
+			},
+			want: `
+| Name               | Required |
+| ------------------ | :------: |
+| region             |   yes    |
+| availability_zones |    no    |
+`,
+		},
+		{
+			name: "minimum width",
+			table: &Table{
+				Columns: []Column{{Title: "A"}, {Title: "B", Align: AlignCenter}},
+				Rows:    []Row{{Cells: []Cell{plain("x"), plain("y")}}},
+			},
+			want: `
+| A   |  B  |
+| --- | :-: |
+| x   |  y  |
+`,
+		},
+		{
+			name: "wide characters",
+			table: &Table{
+				Columns: []Column{{Title: "Name"}, {Title: "Description"}},
+				Rows: []Row{
+					{Cells: []Cell{plain("a"), plain("日本語")}},
+					{Cells: []Cell{plain("b"), plain("🚀 fast")}},
+				},
+			},
+			want: `
+| Name | Description |
+| ---- | ----------- |
+| a    | 日本語      |
+| b    | 🚀 fast     |
+`,
+		},
+		{
+			name: "one line cells",
+			table: &Table{
+				Columns: []Column{{Title: "Default"}, {Title: "Used by"}, {Title: "Description"}},
+				Rows: []Row{{Cells: []Cell{
+					code(formatValue(map[string]interface{}{"Name": "a|b", "ports": []interface{}{int64(80)}})),
+					codeLines([]string{"aws_vpc.this", "output.id"}),
+					plain("First line.\nSecond <line> & more."),
+				}}},
+			},
+			want: "\n" +
+				"| Default                           | Used by                     | Description                                 |\n" +
+				"| --------------------------------- | --------------------------- | ------------------------------------------- |\n" +
+				"| `{ Name = \"a\\|b\", ports = [80] }` | `aws_vpc.this`, `output.id` | First line. Second &lt;line&gt; &amp; more. |\n",
+		},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		markdown{}.table(&b, tt.table)
+		if got := b.String(); got != tt.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/pkg/errors"
+)
+
+// Module is the parsed interface of a Terraform module.
+type Module struct {
+	Inputs          []HCLVar
+	Outputs         []HCLVar
+	RequiredVersion string
+	Providers       []ProviderRequirement
+	ProviderConfigs []ProviderConfig
+	Resources       []Resource
+	Calls           []ModuleCall
+	Locals          []Local
+	Migrations      []Migration
+	Checks          []Check
+	// SettingsRefs are the references of the terraform blocks, such as
+	// those of a backend configuration.
+	SettingsRefs []Reference
+	// NullableUnknown is set when the source does not record whether the
+	// inputs are nullable, as in the JSON of a plan.
+	NullableUnknown bool
+}
+
+// Resource is a managed resource or a data source.
+type Resource struct {
+	Mode string // "managed", "data" or "ephemeral"
+	Type string
+	Name string
+	Refs []Reference
+	// Description is the description of the resource type in the provider
+	// schema, if known.
+	Description string
+	// Preconditions and Postconditions are the condition blocks of the
+	// lifecycle block.
+	Preconditions  []Validation
+	Postconditions []Validation
+	Pos            hcl.Range
+}
+
+// Address returns the address of the resource within its module, e.g.
+// "aws_vpc.this", "data.aws_ami.ubuntu" or "ephemeral.random_password.db".
+func (r Resource) Address() string {
+	if r.Mode != "managed" {
+		return r.Mode + "." + r.Type + "." + r.Name
+	}
+	return r.Type + "." + r.Name
+}
+
+// ModuleCall is a module block.
+type ModuleCall struct {
+	Name    string
+	Source  string
+	Version string
+	Refs    []Reference
+	Module  *Module // Called module, if the loader resolves it.
+	Pos     hcl.Range
+}
+
+// Local is a named value of a locals block.
+type Local struct {
+	Name string
+	Refs []Reference
+	Pos  hcl.Range
+}
+
+// Check is a check block.
+type Check struct {
+	Name    string
+	Asserts []Validation
+	Refs    []Reference
+	// Preconditions and Postconditions are the condition blocks of the
+	// lifecycle block of the scoped data source, if the check has one.
+	Preconditions  []Validation
+	Postconditions []Validation
+	Pos            hcl.Range
+}
+
+// Kinds of migrations.
+const (
+	migrationMoved   = "moved"
+	migrationRemoved = "removed"
+	migrationImport  = "import"
+)
+
+// Migration is a moved, removed or import block, which changes the state of
+// existing deployments when they upgrade to the module.
+type Migration struct {
+	Kind string
+	From string // Previous address of a moved object, or the removed address.
+	To   string // New address of a moved object, or the import target.
+	ID   string // ID of an imported object.
+	// Destroy is whether a removed object is destroyed rather than only
+	// forgotten by Terraform.
+	Destroy bool
+	Pos     hcl.Range
+}
+
+// ProviderRequirement is a provider the module depends on, from
+// required_providers or, for Terraform 0.11, a provider block's version.
+type ProviderRequirement struct {
+	Name    string
+	Source  string
+	Version string
+}
+
+// ProviderConfig is a provider block.
+type ProviderConfig struct {
+	Name  string
+	Alias string
+	Refs  []Reference
+	Pos   hcl.Range
+}
+
+// Address returns the address of the provider configuration, e.g.
+// "provider.aws" or "provider.aws.east".
+func (p ProviderConfig) Address() string {
+	if p.Alias != "" {
+		return "provider." + p.Name + "." + p.Alias
+	}
+	return "provider." + p.Name
+}
+
+// addProvider records a provider requirement, merging it with an earlier
+// one for the same provider.
+func (m *Module) addProvider(p ProviderRequirement) {
+	for i, q := range m.Providers {
+		if q.Name != p.Name {
+			continue
+		}
+		if q.Source == "" {
+			m.Providers[i].Source = p.Source
+		}
+		if q.Version == "" {
+			m.Providers[i].Version = p.Version
+		}
+		return
+	}
+	m.Providers = append(m.Providers, p)
+}
+
+// fileSource gives access to the configuration files of a module.
+type fileSource interface {
+	// Files returns the sorted names of the module's configuration files.
+	Files() ([]string, error)
+	// ReadFile returns the content of one of the files.
+	ReadFile(name string) ([]byte, error)
+	// String describes the source for error messages.
+	String() string
+}
+
+// isConfigFile reports whether name is a Terraform configuration file.
+func isConfigFile(name string) bool {
+	return strings.HasSuffix(name, ".tf") || strings.HasSuffix(name, ".tf.json")
+}
+
+// dirSource reads a module from a directory of the working tree.
+type dirSource string
+
+func (d dirSource) Files() ([]string, error) {
+	infos, err := ioutil.ReadDir(string(d))
+	if err != nil {
+		return nil, errors.Wrap(err, "read dir")
+	}
+	var names []string
+	for _, info := range infos {
+		if !info.IsDir() && isConfigFile(info.Name()) {
+			names = append(names, info.Name())
+		}
+	}
+	return names, nil
+}
+
+func (d dirSource) ReadFile(name string) ([]byte, error) {
+	raw, err := ioutil.ReadFile(filepath.Join(string(d), name))
+	return raw, errors.Wrap(err, "read file")
+}
+
+func (d dirSource) String() string {
+	return string(d)
+}
+
+// gitSource reads a module as of a git revision from the object database,
+// without touching the working tree. Dir is relative to the current
+// directory, which may also be a bare repository.
+type gitSource struct {
+	Ref string
+	Dir string
+}
+
+// git runs a git command and returns its standard output.
+func git(args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "git %s: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// treePath returns the repository path of the module directory.
+func (g gitSource) treePath() (string, error) {
+	prefix, err := git("rev-parse", "--show-prefix")
+	if err != nil {
+		return "", err
+	}
+	p := path.Join(strings.TrimSpace(string(prefix)), filepath.ToSlash(g.Dir))
+	if strings.HasPrefix(p, "..") {
+		return "", errors.Errorf("%s is outside the repository", g.Dir)
+	}
+	if p == "." {
+		p = ""
+	}
+	return p, nil
+}
+
+func (g gitSource) Files() ([]string, error) {
+	dir, err := g.treePath()
+	if err != nil {
+		return nil, err
+	}
+	out, err := git("ls-tree", "--full-tree", "--name-only", g.Ref+":"+dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, name := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if isConfigFile(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (g gitSource) ReadFile(name string) ([]byte, error) {
+	dir, err := g.treePath()
+	if err != nil {
+		return nil, err
+	}
+	return git("cat-file", "blob", g.Ref+":"+path.Join(dir, name))
+}
+
+func (g gitSource) String() string {
+	return g.Ref + ":" + g.Dir
+}
+
+// exists reports whether the module directory exists at the revision.
+func (g gitSource) exists() (bool, error) {
+	dir, err := g.treePath()
+	if err != nil || dir == "" {
+		return err == nil, err
+	}
+	out, err := git("ls-tree", "-d", "--full-tree", "--name-only", g.Ref, "--", dir)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(out)) == dir, nil
+}
+
+// sourceFor returns the source of a module given on the command line: a
+// directory if one exists at arg, otherwise the module at dir as of the git
+// revision arg.
+func sourceFor(arg, dir string) fileSource {
+	if info, err := os.Stat(arg); err == nil && info.IsDir() {
+		return dirSource(arg)
+	}
+	return gitSource{Ref: arg, Dir: dir}
+}
+
+// loadModule parses all configuration files of a module.
+func loadModule(src fileSource) (*Module, error) {
+	names, err := src.Files()
+	if err != nil {
+		return nil, errors.Wrapf(err, "list %s", src)
+	}
+	m := &Module{}
+	for _, name := range names {
+		raw, err := src.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		f, err := parseHCL(raw, name)
+		if err == nil {
+			err = m.decode(f)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s", name)
+		}
+	}
+	return m, nil
+}
+
+// decode adds the blocks of a configuration file to a module.
+func (m *Module) decode(f *configFile) error {
+	inputs, err := decodeVars(f, "variable")
+	if err != nil {
+		return err
+	}
+	outputs, err := decodeVars(f, "output")
+	if err != nil {
+		return err
+	}
+	resources, err := decodeResources(f)
+	if err != nil {
+		return err
+	}
+	calls, err := decodeCalls(f)
+	if err != nil {
+		return err
+	}
+	locals, err := decodeLocals(f)
+	if err != nil {
+		return err
+	}
+	migrations, err := decodeMigrations(f)
+	if err != nil {
+		return err
+	}
+	checks, err := decodeChecks(f)
+	if err != nil {
+		return err
+	}
+	m.Inputs = append(m.Inputs, inputs...)
+	m.Outputs = append(m.Outputs, outputs...)
+	m.Resources = append(m.Resources, resources...)
+	m.Calls = append(m.Calls, calls...)
+	m.Locals = append(m.Locals, locals...)
+	m.Migrations = append(m.Migrations, migrations...)
+	m.Checks = append(m.Checks, checks...)
+	return decodeRequirements(f, m)
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/pkg/errors"
+)
+
+// providerSchemas is the output of `terraform providers schema -json`,
+// reduced to the resource, data source and ephemeral resource schemas.
+type providerSchemas struct {
+	Providers map[string]struct {
+		Resources          map[string]resourceSchema `json:"resource_schemas"`
+		DataSources        map[string]resourceSchema `json:"data_source_schemas"`
+		EphemeralResources map[string]resourceSchema `json:"ephemeral_resource_schemas"`
+	} `json:"provider_schemas"`
+}
+
+// resourceSchema is the schema of a resource type or data source.
+type resourceSchema struct {
+	Block schemaBlock `json:"block"`
+}
+
+// schemaBlock is the schema of a block: its attributes and nested blocks.
+type schemaBlock struct {
+	Attributes  map[string]schemaAttribute `json:"attributes"`
+	BlockTypes  map[string]nestedBlock     `json:"block_types"`
+	Description string                     `json:"description"`
+}
+
+// schemaAttribute is an attribute of a block. Type is a type in the JSON
+// encoding of cty, e.g. "string" or ["list","string"].
+type schemaAttribute struct {
+	Type interface{} `json:"type"`
+}
+
+// nestedBlock is a block type nested in a block.
+type nestedBlock struct {
+	NestingMode string      `json:"nesting_mode"`
+	Block       schemaBlock `json:"block"`
+}
+
+// readProviderSchemas reads a provider schema dump and indexes the schemas
+// by resource address prefix, e.g. "aws_vpc", "data.aws_ami" or
+// "ephemeral.random_password".
+func readProviderSchemas(path string) (map[string]resourceSchema, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "read file")
+	}
+	var ps providerSchemas
+	if err := json.Unmarshal(raw, &ps); err != nil {
+		return nil, errors.Wrap(err, "parse")
+	}
+	schemas := map[string]resourceSchema{}
+	for _, p := range ps.Providers {
+		for name, s := range p.Resources {
+			schemas[name] = s
+		}
+		for name, s := range p.DataSources {
+			schemas["data."+name] = s
+		}
+		for name, s := range p.EphemeralResources {
+			schemas["ephemeral."+name] = s
+		}
+	}
+	return schemas, nil
+}
+
+// ctyType converts a type in the JSON encoding of cty to a type expression.
+func ctyType(v interface{}) (*TypeExpr, error) {
+	switch v := v.(type) {
+	case string:
+		switch v {
+		case "string", "number", "bool":
+			return &TypeExpr{Kind: v}, nil
+		case "dynamic":
+			return anyType, nil
+		}
+	case []interface{}:
+		if len(v) != 2 {
+			break
+		}
+		kind, _ := v[0].(string)
+		switch kind {
+		case "list", "set", "map":
+			elem, err := ctyType(v[1])
+			if err != nil {
+				return nil, err
+			}
+			return &TypeExpr{Kind: kind, Elem: elem}, nil
+		case "tuple":
+			elems, _ := v[1].([]interface{})
+			t := &TypeExpr{Kind: kind}
+			for _, e := range elems {
+				et, err := ctyType(e)
+				if err != nil {
+					return nil, err
+				}
+				t.Elems = append(t.Elems, et)
+			}
+			return t, nil
+		case "object":
+			attrs, _ := v[1].(map[string]interface{})
+			names := make([]string, 0, len(attrs))
+			for name := range attrs {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			t := &TypeExpr{Kind: kind}
+			for _, name := range names {
+				at, err := ctyType(attrs[name])
+				if err != nil {
+					return nil, err
+				}
+				t.Attrs = append(t.Attrs, TypeAttr{Name: name, Type: at})
+			}
+			return t, nil
+		}
+	}
+	return nil, errors.Errorf("unsupported type %v", v)
+}
+
+// blockType returns the object type of the values of a block.
+func blockType(b schemaBlock) (*TypeExpr, error) {
+	var names []string
+	for name := range b.Attributes {
+		names = append(names, name)
+	}
+	for name := range b.BlockTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	t := &TypeExpr{Kind: "object"}
+	for _, name := range names {
+		at, err := attrType(b, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "attribute %q", name)
+		}
+		t.Attrs = append(t.Attrs, TypeAttr{Name: name, Type: at})
+	}
+	return t, nil
+}
+
+// attrType returns the type of an attribute or nested block of a block.
+func attrType(b schemaBlock, name string) (*TypeExpr, error) {
+	if a, ok := b.Attributes[name]; ok {
+		return ctyType(a.Type)
+	}
+	nb, ok := b.BlockTypes[name]
+	if !ok {
+		return nil, errors.Errorf("no attribute %q", name)
+	}
+	t, err := blockType(nb.Block)
+	if err != nil {
+		return nil, err
+	}
+	switch nb.NestingMode {
+	case "list", "set", "map":
+		return &TypeExpr{Kind: nb.NestingMode, Elem: t}, nil
+	}
+	return t, nil
+}
+
+// outputType infers the type of an output whose value is an attribute of
+// a resource, e.g. aws_vpc.this.id, or of a splat, e.g. aws_subnet.a[*].id.
+// Nested attributes and blocks are followed to the end of the path, e.g.
+// aws_vpc.this.tags["Name"] is a string. It returns "" if the type is
+// unknown.
+func outputType(o HCLVar, schemas map[string]resourceSchema) string {
+	expr, diags := hclsyntax.ParseExpression([]byte(o.Value), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return ""
+	}
+	var steps hcl.Traversal
+	list := false
+	switch e := expr.(type) {
+	case *hclsyntax.ScopeTraversalExpr:
+		steps = e.Traversal
+	case *hclsyntax.SplatExpr:
+		src, ok := e.Source.(*hclsyntax.ScopeTraversalExpr)
+		each, ok2 := e.Each.(*hclsyntax.RelativeTraversalExpr)
+		if !ok || !ok2 {
+			return ""
+		}
+		steps, list = append(src.Traversal[:len(src.Traversal):len(src.Traversal)], each.Traversal...), true
+	default:
+		return ""
+	}
+
+	prefix, n := steps.RootName(), 1
+	if prefix == "data" || prefix == "ephemeral" {
+		if len(steps) <= n {
+			return ""
+		}
+		a, ok := steps[n].(hcl.TraverseAttr)
+		if !ok {
+			return ""
+		}
+		prefix, n = prefix+"."+a.Name, n+1
+	}
+	s, ok := schemas[prefix]
+	if !ok || len(steps) <= n {
+		return ""
+	}
+	rest := steps[n+1:]
+	if _, ok := steps[n].(hcl.TraverseAttr); !ok || len(rest) == 0 {
+		return ""
+	}
+	// Skip the instance key of a resource with count or for_each.
+	if _, ok := rest[0].(hcl.TraverseIndex); ok && !list {
+		rest = rest[1:]
+	}
+	first, ok := rest[0].(hcl.TraverseAttr)
+	if !ok {
+		return ""
+	}
+	t, err := attrType(s.Block, first.Name)
+	if err != nil {
+		return ""
+	}
+	for _, step := range rest[1:] {
+		if t = stepType(t, step); t == nil {
+			return ""
+		}
+	}
+	if list {
+		t = &TypeExpr{Kind: "list", Elem: t}
+	}
+	return t.String()
+}
+
+// stepType returns the type of the attribute or element of a value of type
+// t that a traversal step selects, or nil if it is unknown.
+func stepType(t *TypeExpr, step hcl.Traverser) *TypeExpr {
+	var key interface{}
+	switch step := step.(type) {
+	case hcl.TraverseAttr:
+		key = step.Name
+	case hcl.TraverseIndex:
+		key = ctyValue(step.Key)
+	default:
+		return nil
+	}
+	switch t.Kind {
+	case "list":
+		if _, ok := key.(int64); ok {
+			return t.Elem
+		}
+	case "map":
+		return t.Elem
+	case "tuple":
+		if i, ok := key.(int64); ok && i >= 0 && i < int64(len(t.Elems)) {
+			return t.Elems[i]
+		}
+	case "object":
+		for _, a := range t.Attrs {
+			if a.Name == key {
+				return a.Type
+			}
+		}
+	}
+	return nil
+}
+
+// applyProviderSchemas fills in the descriptions of the resources of a
+// module and the types of the outputs that expose a resource attribute.
+func applyProviderSchemas(m *Module, schemas map[string]resourceSchema) {
+	for i, r := range m.Resources {
+		prefix := r.Type
+		if r.Mode != "managed" {
+			prefix = r.Mode + "." + r.Type
+		}
+		m.Resources[i].Description = schemas[prefix].Block.Description
+	}
+	for i, o := range m.Outputs {
+		if o.VarType == "" {
+			m.Outputs[i].VarType = outputType(o, schemas)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import "testing"
+
+func TestOutputType(t *testing.T) {
+	schemas := map[string]resourceSchema{
+		"aws_vpc": {Block: schemaBlock{
+			Attributes: map[string]schemaAttribute{
+				"id":   {Type: "string"},
+				"tags": {Type: []interface{}{"map", "string"}},
+			},
+			BlockTypes: map[string]nestedBlock{
+				"ipv6": {NestingMode: "list", Block: schemaBlock{
+					Attributes: map[string]schemaAttribute{"cidr": {Type: "string"}},
+				}},
+			},
+		}},
+		"data.aws_ami": {Block: schemaBlock{
+			Attributes: map[string]schemaAttribute{"id": {Type: "string"}},
+		}},
+		"ephemeral.random_password": {Block: schemaBlock{
+			Attributes: map[string]schemaAttribute{"result": {Type: "string"}},
+		}},
+	}
+	tests := []struct {
+		value, want string
+	}{
+		{"aws_vpc.this.id", "string"},
+		{`aws_vpc.this.tags["Name"]`, "string"},
+		{"aws_vpc.this[0].id", "string"},
+		{`aws_vpc.this["a"].id`, "string"},
+		{"aws_vpc.this[*].id", "list(string)"},
+		{"aws_vpc.this.ipv6[0].cidr", "string"},
+		{"aws_vpc.this.ipv6", "list(object({cidr = string}))"},
+		{"data.aws_ami.ubuntu.id", "string"},
+		{"ephemeral.random_password.db.result", "string"},
+		{"aws_vpc.this", ""},
+		{"aws_vpc.this.missing", ""},
+		{"aws_subnet.this.id", ""},
+		{"var.name", ""},
+		{"data", ""},
+		{"ephemeral", ""},
+		{"data.aws_ami", ""},
+		{"data.aws_ami.ubuntu", ""},
+		{`"literal"`, ""},
+	}
+	for _, tt := range tests {
+		if got := outputType(HCLVar{Value: tt.value}, schemas); got != tt.want {
+			t.Errorf("outputType(%s) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
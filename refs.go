@@ -0,0 +1,241 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// Reference is a reference from a block of the configuration to another
+// object, such as `var.name` or `aws_subnet.a.id`.
+type Reference struct {
+	// Traversal is the reference as written, e.g. "module.vpc.vpc_id".
+	Traversal string
+	// Attr is the top-level attribute or nested block of the referring
+	// block in which the reference appears.
+	Attr string
+	// Pos is the range of the reference in its file.
+	Pos hcl.Range
+}
+
+// Subject returns the address of the referenced object: "var.x",
+// "local.x", "module.x", "data.t.x" or "t.x" for a resource. References
+// to count, each, self, path and terraform have no subject.
+func (r Reference) Subject() string {
+	parts := splitTraversal(r.Traversal)
+	if len(parts) < 2 {
+		return ""
+	}
+	switch parts[0] {
+	case "count", "each", "self", "path", "terraform":
+		return ""
+	case "var", "local", "module":
+		return parts[0] + "." + parts[1]
+	case "data", "ephemeral":
+		if len(parts) < 3 {
+			return ""
+		}
+		return strings.Join(parts[:3], ".")
+	}
+	return parts[0] + "." + parts[1]
+}
+
+// splitTraversal splits a traversal into its names, dropping index steps.
+func splitTraversal(t string) []string {
+	var parts []string
+	for _, p := range strings.Split(t, ".") {
+		if i := strings.IndexByte(p, '['); i >= 0 {
+			p = p[:i]
+		}
+		parts = append(parts, p)
+	}
+	return parts
+}
+
+// traversalString formats a traversal as written, e.g. "aws_subnet.a[0].id".
+func traversalString(t hcl.Traversal) string {
+	var b strings.Builder
+	for _, step := range t {
+		switch step := step.(type) {
+		case hcl.TraverseRoot:
+			b.WriteString(step.Name)
+		case hcl.TraverseAttr:
+			b.WriteString("." + step.Name)
+		case hcl.TraverseIndex:
+			b.WriteString("[" + formatValue(ctyValue(step.Key)) + "]")
+		case hcl.TraverseSplat:
+			b.WriteString("[*]")
+		}
+	}
+	return b.String()
+}
+
+// exprRefs returns the references in an expression, which appears in the
+// given top-level attribute or nested block of the referring block.
+// Variables bound by for expressions are not references.
+func exprRefs(expr hcl.Expression, attr string) []Reference {
+	var refs []Reference
+	for _, t := range expr.Variables() {
+		refs = append(refs, Reference{Traversal: traversalString(t), Attr: attr, Pos: t.SourceRange()})
+	}
+	return refs
+}
+
+// bodyRefs returns the references in a block body, in the order of the
+// source.
+func bodyRefs(body hcl.Body) []Reference {
+	var refs []Reference
+	if sb, ok := body.(*hclsyntax.Body); ok {
+		refs = syntaxBodyRefs(sb, "", nil)
+	} else {
+		// JSON has no syntax for nested blocks, which read as attributes.
+		attrs, _ := body.JustAttributes()
+		for _, a := range attrs {
+			refs = append(refs, exprRefs(a.Expr, a.Name)...)
+		}
+	}
+	sort.SliceStable(refs, func(i, j int) bool { return refs[i].Pos.Start.Byte < refs[j].Pos.Start.Byte })
+	return refs
+}
+
+// syntaxBodyRefs returns the references in a body of the native syntax,
+// attributing those of nested blocks to the top-level block, or to attr if
+// set. The iterators of enclosing dynamic blocks, named in bound, are not
+// references.
+func syntaxBodyRefs(body *hclsyntax.Body, attr string, bound map[string]bool) []Reference {
+	var refs []Reference
+	for name, a := range body.Attributes {
+		top := attr
+		if top == "" {
+			top = name
+		}
+		for _, ref := range exprRefs(a.Expr, top) {
+			if !bound[splitTraversal(ref.Traversal)[0]] {
+				refs = append(refs, ref)
+			}
+		}
+	}
+	for _, block := range body.Blocks {
+		top := attr
+		if top == "" {
+			top = block.Type
+		}
+		inner := bound
+		if block.Type == "dynamic" && len(block.Labels) > 0 {
+			if attr == "" {
+				top = block.Labels[0]
+			}
+			inner = map[string]bool{dynamicIterator(block): true}
+			for name := range bound {
+				inner[name] = true
+			}
+		}
+		refs = append(refs, syntaxBodyRefs(block.Body, top, inner)...)
+	}
+	return refs
+}
+
+// dynamicIterator returns the name of the iterator of a dynamic block: the
+// iterator attribute if set, the block label otherwise.
+func dynamicIterator(block *hclsyntax.Block) string {
+	if a, ok := block.Body.Attributes["iterator"]; ok {
+		if name := hcl.ExprAsKeyword(a.Expr); name != "" {
+			return name
+		}
+	}
+	return block.Labels[0]
+}
+
+// referrer is an object of the configuration that can reference others.
+type referrer struct {
+	Address string
+	Kind    string // One of the diagram node kinds.
+	Refs    []Reference
+}
+
+// referrers returns the objects of a module with their references: the
+// validations of inputs, locals, resources, module calls, outputs,
+// provider configurations, check blocks and terraform settings.
+func referrers(m *Module) []referrer {
+	var rs []referrer
+	for _, v := range m.Inputs {
+		if len(v.Refs) > 0 {
+			rs = append(rs, referrer{"var." + v.Name, nodeInput, v.Refs})
+		}
+	}
+	for _, l := range m.Locals {
+		rs = append(rs, referrer{"local." + l.Name, nodeLocal, l.Refs})
+	}
+	for _, r := range m.Resources {
+		kind := nodeResource
+		if r.Mode != "managed" {
+			kind = nodeData
+		}
+		rs = append(rs, referrer{r.Address(), kind, r.Refs})
+	}
+	for _, c := range m.Calls {
+		rs = append(rs, referrer{"module." + c.Name, nodeModule, c.Refs})
+	}
+	for _, o := range m.Outputs {
+		rs = append(rs, referrer{"output." + o.Name, nodeOutput, o.Refs})
+	}
+	for _, p := range m.ProviderConfigs {
+		rs = append(rs, referrer{p.Address(), nodeProvider, p.Refs})
+	}
+	for _, c := range m.Checks {
+		rs = append(rs, referrer{"check." + c.Name, nodeCheck, c.Refs})
+	}
+	if len(m.SettingsRefs) > 0 {
+		rs = append(rs, referrer{"terraform", nodeSettings, m.SettingsRefs})
+	}
+	return rs
+}
+
+// Usage is an object referencing an input, and the attributes it uses the
+// input in. Locals and outputs have no attributes.
+type Usage struct {
+	Address string
+	Attrs   []string
+}
+
+// inputUsages returns the objects referencing each input, in the order of
+// the configuration.
+func inputUsages(m *Module) map[string][]Usage {
+	usages := map[string][]Usage{}
+	for _, r := range referrers(m) {
+		// Locals and outputs are single expressions.
+		withAttrs := r.Kind != nodeLocal && r.Kind != nodeOutput
+		var order []string
+		attrs := map[string][]string{}
+		for _, ref := range r.Refs {
+			subject := ref.Subject()
+			// A validation referencing its own variable is no usage.
+			if !strings.HasPrefix(subject, "var.") || subject == r.Address {
+				continue
+			}
+			name := strings.TrimPrefix(subject, "var.")
+			if _, ok := attrs[name]; !ok {
+				order = append(order, name)
+				attrs[name] = nil
+			}
+			if withAttrs && !containsString(attrs[name], ref.Attr) {
+				attrs[name] = append(attrs[name], ref.Attr)
+			}
+		}
+		for _, name := range order {
+			usages[name] = append(usages[name], Usage{Address: r.Address, Attrs: attrs[name]})
+		}
+	}
+	return usages
+}
+
+func containsString(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+func TestReferenceSubject(t *testing.T) {
+	tests := []struct {
+		traversal, want string
+	}{
+		{"var.region", "var.region"},
+		{"var.tags[\"Name\"]", "var.tags"},
+		{"local.name", "local.name"},
+		{"module.vpc.vpc_id", "module.vpc"},
+		{"module.vpc[0].vpc_id", "module.vpc"},
+		{"aws_vpc.this.id", "aws_vpc.this"},
+		{"aws_subnet.a[*].id", "aws_subnet.a"},
+		{"data.aws_ami.ubuntu.id", "data.aws_ami.ubuntu"},
+		{"ephemeral.random_password.db.result", "ephemeral.random_password.db"},
+		{"data.aws_ami", ""},
+		{"count.index", ""},
+		{"each.value", ""},
+		{"self.id", ""},
+		{"path.module", ""},
+		{"terraform.workspace", ""},
+		{"aws_vpc", ""},
+	}
+	for _, tt := range tests {
+		if got := (Reference{Traversal: tt.traversal}).Subject(); got != tt.want {
+			t.Errorf("Reference{%s}.Subject() = %q, want %q", tt.traversal, got, tt.want)
+		}
+	}
+}
+
+func TestBodyRefs(t *testing.T) {
+	src := `
+resource "aws_security_group" "this" {
+  name   = "${var.prefix}-sg"
+  vpc_id = module.vpc.vpc_id
+  tags   = { for k, v in var.tags : k => upper(v) }
+
+  dynamic "ingress" {
+    for_each = var.ports
+    content {
+      from_port = ingress.value
+      cidr      = local.cidr
+    }
+  }
+
+  dynamic "egress" {
+    for_each = var.egress
+    iterator = rule
+    content {
+      to_port = rule.value
+      egress  = egress.value
+    }
+  }
+
+  lifecycle {
+    precondition {
+      condition = data.aws_vpc.this.id != ""
+    }
+  }
+}
+`
+	file, diags := hclsyntax.ParseConfig([]byte(src), "main.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+	body := file.Body.(*hclsyntax.Body).Blocks[0].Body
+	var got []string
+	for _, ref := range bodyRefs(body) {
+		got = append(got, ref.Attr+": "+ref.Traversal)
+	}
+	want := []string{
+		"name: var.prefix",
+		"vpc_id: module.vpc.vpc_id",
+		"tags: var.tags",
+		"ingress: var.ports",
+		"ingress: local.cidr",
+		"egress: var.egress",
+		"egress: egress.value",
+		"lifecycle: data.aws_vpc.this.id",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bodyRefs = %q, want %q", got, want)
+	}
+}
+
+func TestInputUsages(t *testing.T) {
+	m := &Module{
+		Inputs: []HCLVar{
+			{Name: "cidr", Refs: []Reference{{Traversal: "var.cidr", Attr: "condition"}, {Traversal: "var.prefix", Attr: "condition"}}},
+		},
+		Locals: []Local{{Name: "name", Refs: []Reference{{Traversal: "var.prefix"}}}},
+		Resources: []Resource{{Mode: "managed", Type: "aws_vpc", Name: "this", Refs: []Reference{
+			{Traversal: "var.cidr", Attr: "cidr_block"},
+			{Traversal: "local.name", Attr: "tags"},
+			{Traversal: "var.prefix", Attr: "tags"},
+			{Traversal: "var.cidr", Attr: "tags"},
+			{Traversal: "var.cidr", Attr: "cidr_block"},
+		}}},
+		Outputs: []HCLVar{{Name: "cidr", Refs: []Reference{{Traversal: "var.cidr", Attr: "value"}}}},
+	}
+	want := map[string][]Usage{
+		"prefix": {
+			{Address: "var.cidr", Attrs: []string{"condition"}},
+			{Address: "local.name"},
+			{Address: "aws_vpc.this", Attrs: []string{"tags"}},
+		},
+		"cidr": {
+			{Address: "aws_vpc.this", Attrs: []string{"cidr_block", "tags"}},
+			{Address: "output.cidr"},
+		},
+	}
+	if got := inputUsages(m); !reflect.DeepEqual(got, want) {
+		t.Errorf("inputUsages = %v, want %v", got, want)
+	}
+}
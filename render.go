@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// Renderer writes a Document in some output format.
+type Renderer interface {
+	Render(w io.Writer, doc *Document) error
+}
+
+// renderers maps the -format flag values to their Renderer.
+var renderers = map[string]Renderer{
+	"markdown": markupRenderer{markdown{}},
+	"asciidoc": markupRenderer{asciidoc{}},
+	"rst":      markupRenderer{rst{}},
+	"html":     htmlPage{},
+	"dot":      dotRenderer{},
+}
+
+// formatNames returns the sorted list of supported -format values.
+func formatNames() []string {
+	names := make([]string, 0, len(renderers))
+	for name := range renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// markup emits the elements of a lightweight text markup language.
+//
+// Section levels start at 1 for the top-level sections below the title.
+type markup interface {
+	title(b *bytes.Buffer, title string)
+	heading(b *bytes.Buffer, level int, title, anchor string)
+	paragraph(b *bytes.Buffer, p Paragraph)
+	list(b *bytes.Buffer, l List)
+	table(b *bytes.Buffer, t *Table)
+	code(b *bytes.Buffer, c CodeBlock)
+	diagram(b *bytes.Buffer, d *Diagram)
+	contents(b *bytes.Buffer, c Contents)
+}
+
+// markupRenderer renders a document by walking it with a markup.
+type markupRenderer struct {
+	m markup
+}
+
+// Render implements Renderer.
+func (r markupRenderer) Render(w io.Writer, doc *Document) error {
+	anchorSections(doc)
+	var b bytes.Buffer
+	if doc.Title != "" {
+		r.m.title(&b, doc.Title)
+	}
+	for _, s := range doc.Sections {
+		r.section(&b, s, 1)
+	}
+	_, err := b.WriteTo(w)
+	return errors.Wrap(err, "write stream")
+}
+
+func (r markupRenderer) section(b *bytes.Buffer, s *Section, level int) {
+	r.m.heading(b, level, s.Title, s.Anchor)
+	for _, blk := range s.Blocks {
+		switch blk := blk.(type) {
+		case *Section:
+			r.section(b, blk, level+1)
+		case Paragraph:
+			r.m.paragraph(b, blk)
+		case List:
+			r.m.list(b, blk)
+		case *Table:
+			r.m.table(b, blk)
+		case CodeBlock:
+			r.m.code(b, blk)
+		case *Diagram:
+			r.m.diagram(b, blk)
+		case Contents:
+			r.m.contents(b, blk)
+		}
+	}
+}
+
+// joinText renders each run of a paragraph or cell with fn and concatenates them.
+func joinText(texts []Text, fn func(Text) string) string {
+	parts := make([]string, 0, len(texts))
+	for _, t := range texts {
+		parts = append(parts, fn(t))
+	}
+	return strings.Join(parts, "")
+}
+
+// slugify turns a title into an anchor name the way GitHub does for
+// headings: lower case, without punctuation, and with a dash for each space.
+func slugify(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r == ' ':
+			b.WriteByte('-')
+		case r == '-', r == '_', unicode.IsLetter(r), unicode.IsDigit(r), unicode.IsMark(r):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// targetID returns an anchor as an AsciiDoc ID or a reStructuredText
+// target name, which must start with a letter. Slugs of titles starting
+// with a symbol or a digit, such as "-launch", get an "id" prefix.
+func targetID(anchor string) string {
+	r, _ := utf8.DecodeRuneInString(anchor)
+	switch {
+	case unicode.IsLetter(r):
+		return anchor
+	case r == '-':
+		return "id" + anchor
+	}
+	return "id-" + anchor
+}
+
+// slugger hands out unique anchors. Like GitHub, it suffixes repeated
+// slugs with -1, -2 and so on.
+type slugger map[string]bool
+
+func (sl slugger) slug(title string) string {
+	base := slugify(title)
+	slug := base
+	for i := 1; sl[slug]; i++ {
+		slug = base + "-" + strconv.Itoa(i)
+	}
+	sl[slug] = true
+	return slug
+}
+
+// anchorSections gives an anchor to every section of a document that has
+// none, in document order, so that anchors only change when the headings
+// before them do.
+func anchorSections(doc *Document) {
+	sl := slugger{}
+	if doc.Title != "" {
+		sl.slug(doc.Title)
+	}
+	var walk func(sections []*Section)
+	walk = func(sections []*Section) {
+		for _, s := range sections {
+			if s.Anchor == "" {
+				s.Anchor = sl.slug(s.Title)
+			} else {
+				sl[s.Anchor] = true
+			}
+			var subs []*Section
+			for _, blk := range s.Blocks {
+				if sub, ok := blk.(*Section); ok {
+					subs = append(subs, sub)
+				}
+			}
+			walk(subs)
+		}
+	}
+	walk(doc.Sections)
+}
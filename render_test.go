@@ -0,0 +1,137 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		title, want string
+	}{
+		{"Overview", "overview"},
+		{"Guarantees and Assumptions", "guarantees-and-assumptions"},
+		{"check.health", "checkhealth"},
+		{"aws_vpc.this", "aws_vpcthis"},
+		{"subnet_ids", "subnet_ids"},
+		{"Required inputs", "required-inputs"},
+		{"What's new?", "whats-new"},
+		{"  two  spaces ", "--two--spaces-"},
+		{"a-b", "a-b"},
+		{"Über Größe", "über-größe"},
+		{"日本語 見出し", "日本語-見出し"},
+		{"🚀 Launch", "-launch"},
+	}
+	for _, tt := range tests {
+		if got := slugify(tt.title); got != tt.want {
+			t.Errorf("slugify(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestAnchorSections(t *testing.T) {
+	doc := &Document{
+		Title: "Overview",
+		Sections: []*Section{
+			{Title: "Overview"},
+			{Title: "Input", Blocks: []Block{
+				&Section{Title: "region"},
+				&Section{Title: "tags"},
+			}},
+			{Title: "Output", Blocks: []Block{
+				&Section{Title: "region"},
+				&Section{Title: "tags", Anchor: "custom"},
+			}},
+			{Title: "custom"},
+			{Title: "Input"},
+		},
+	}
+	anchorSections(doc)
+
+	var got []string
+	var walk func(sections []*Section)
+	walk = func(sections []*Section) {
+		for _, s := range sections {
+			got = append(got, s.Anchor)
+			for _, blk := range s.Blocks {
+				if sub, ok := blk.(*Section); ok {
+					walk([]*Section{sub})
+				}
+			}
+		}
+	}
+	walk(doc.Sections)
+
+	want := []string{"overview-1", "input", "region", "tags", "output", "region-1", "custom", "custom-1", "input-1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("anchors = %q, want %q", got, want)
+	}
+}
+
+func TestDocumentLayoutContents(t *testing.T) {
+	m := &Module{
+		Inputs:  []HCLVar{{Name: "region", Required: true, Nullable: true}},
+		Outputs: []HCLVar{{Name: "region"}, {Name: "vpc_id"}},
+	}
+	doc := newDocument("Test", m, docOptions{Layout: layoutDocument, Contents: true})
+	contents, ok := doc.Sections[0].Blocks[0].(Contents)
+	if !ok {
+		t.Fatalf("first block of %q is %T, want Contents", doc.Sections[0].Title, doc.Sections[0].Blocks[0])
+	}
+	var output *ContentsEntry
+	for i := range contents {
+		if contents[i].Title == "Output" {
+			output = &contents[i]
+		}
+	}
+	if output == nil {
+		t.Fatal("no Output entry in the table of contents")
+	}
+	want := Contents{{Title: "region", Anchor: "region-1"}, {Title: "vpc_id", Anchor: "vpc_id"}}
+	if !reflect.DeepEqual(output.Entries, want) {
+		t.Errorf("Output entries = %+v, want %+v", output.Entries, want)
+	}
+}
+
+func TestTargetID(t *testing.T) {
+	tests := []struct {
+		anchor, want string
+	}{
+		{"overview", "overview"},
+		{"über-größe", "über-größe"},
+		{"-launch", "id-launch"},
+		{"2024-notes", "id-2024-notes"},
+		{"_private", "id-_private"},
+		{"", "id-"},
+	}
+	for _, tt := range tests {
+		if got := targetID(tt.anchor); got != tt.want {
+			t.Errorf("targetID(%q) = %q, want %q", tt.anchor, got, tt.want)
+		}
+	}
+}
+
+// sampleDocument returns a document using every kind of block, with titles
+// that need escaping and anchors that are not valid IDs as they are.
+func sampleDocument() *Document {
+	return &Document{
+		Title: "my_*module*",
+		Sections: []*Section{
+			{Title: "`Usage` *notes*", Blocks: []Block{Contents{{Title: "🚀 Launch", Anchor: "-launch"}, {Title: "Input", Anchor: "input"}}}},
+			{Title: "🚀 Launch", Blocks: []Block{Paragraph{{Value: "Run "}, {Value: "terraform apply", Code: true}, {Value: "."}}}},
+			{Title: "Input", Blocks: []Block{
+				&Table{
+					Columns: []Column{{Title: "Name"}, {Title: "Required", Align: AlignCenter}},
+					Rows: []Row{
+						{Anchor: "input-region", Cells: []Cell{{{Value: "region", Code: true}}, plain("yes")}},
+						{Cells: []Cell{plain("a|b"), {{Value: "{\n  a = 1\n}", Code: true}}}},
+					},
+				},
+				&Section{Title: "2024 notes", Blocks: []Block{
+					List{{{Value: "Strong", Strong: true}}, {{Value: "docs", Link: "https://example.com"}}},
+					CodeBlock{Lang: "hcl", Code: "x = 1"},
+				}},
+			}},
+		},
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// rst emits reStructuredText as understood by docutils and Sphinx.
+type rst struct{}
+
+// rstUnderlines are the heading adornments for each section level.
+var rstUnderlines = []string{"-", "~", "^", `"`}
+
+// rstEscape backslash-escapes inline markup characters in s. Underscores are
+// only escaped where they could end a reference.
+func rstEscape(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch r {
+		case '\\', '*', '`', '|':
+			b.WriteByte('\\')
+		case '_':
+			next, _ := utf8.DecodeRuneInString(s[i+1:])
+			if i+1 == len(s) || !isWordRune(next) {
+				b.WriteByte('\\')
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || r == '-' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
+
+// rstIndent indents every line of s but the first by n spaces.
+func rstIndent(s string, n int) string {
+	return strings.Replace(s, "\n", "\n"+strings.Repeat(" ", n), -1)
+}
+
+func (rst) title(b *bytes.Buffer, title string) {
+	title = rstEscape(title)
+	line := strings.Repeat("=", textWidth(title))
+	fmt.Fprintf(b, "%s\n%s\n%s\n", line, title, line)
+}
+
+func (rst) heading(b *bytes.Buffer, level int, title, anchor string) {
+	adornment := rstUnderlines[len(rstUnderlines)-1]
+	if level-1 < len(rstUnderlines) {
+		adornment = rstUnderlines[level-1]
+	}
+	title = rstEscape(title)
+	fmt.Fprintf(b, "\n.. _%s:\n\n%s\n%s\n", targetID(anchor), title, strings.Repeat(adornment, textWidth(title)))
+}
+
+// text renders a run. reStructuredText has no strikethrough, so struck
+// text is left as is, and links cannot hold markup, so their text is plain.
+func (rst) text(t Text) string {
+	if t.Link != "" {
+		return "`" + rstEscape(t.Value) + " <" + t.Link + ">`__"
+	}
+	if t.Strong && strings.TrimSpace(t.Value) != "" {
+		return "**" + rstEscape(t.Value) + "**"
+	}
+	if !t.Code {
+		return rstEscape(t.Value)
+	}
+	if strings.TrimSpace(t.Value) == "" {
+		return ""
+	}
+	return "``" + t.Value + "``"
+}
+
+func (r rst) paragraph(b *bytes.Buffer, p Paragraph) {
+	fmt.Fprintf(b, "\n%s\n", joinText(p, r.text))
+}
+
+func (r rst) list(b *bytes.Buffer, l List) {
+	b.WriteString("\n")
+	for _, item := range l {
+		fmt.Fprintf(b, "* %s\n", rstIndent(joinText(item, r.text), 2))
+	}
+}
+
+func (r rst) contents(b *bytes.Buffer, c Contents) {
+	r.contentsList(b, c, "")
+}
+
+// contentsList writes a level of the table of contents. Nested lists are
+// separated from their parent item by blank lines.
+func (r rst) contentsList(b *bytes.Buffer, c Contents, indent string) {
+	b.WriteString("\n")
+	for _, e := range c {
+		fmt.Fprintf(b, "%s* `%s <%s_>`_\n", indent, rstEscape(e.Title), targetID(e.Anchor))
+		if len(e.Entries) > 0 {
+			r.contentsList(b, e.Entries, indent+"  ")
+			b.WriteString("\n")
+		}
+	}
+}
+
+func (r rst) table(b *bytes.Buffer, t *Table) {
+	b.WriteString("\n.. list-table::\n   :header-rows: 1\n\n")
+	for i, c := range t.Columns {
+		r.cell(b, i, rstEscape(c.Title))
+	}
+	for _, row := range t.Rows {
+		for i, cell := range row.Cells {
+			r.cell(b, i, joinText(cell, r.text))
+		}
+	}
+}
+
+// cell writes one list-table cell; the first column starts a new row.
+func (rst) cell(b *bytes.Buffer, col int, content string) {
+	marker := "     -"
+	if col == 0 {
+		marker = "   * -"
+	}
+	if content == "" {
+		fmt.Fprintf(b, "%s\n", marker)
+		return
+	}
+	fmt.Fprintf(b, "%s %s\n", marker, rstIndent(content, 7))
+}
+
+func (rst) code(b *bytes.Buffer, c CodeBlock) {
+	// docutils rejects empty literal blocks.
+	if strings.TrimSpace(c.Code) == "" {
+		return
+	}
+	if c.Lang != "" {
+		fmt.Fprintf(b, "\n.. code-block:: %s\n\n", c.Lang)
+	} else {
+		b.WriteString("\n::\n\n")
+	}
+	fmt.Fprintf(b, "   %s\n", rstIndent(c.Code, 3))
+}
+
+// diagram emits a sphinxcontrib-mermaid directive.
+func (rst) diagram(b *bytes.Buffer, d *Diagram) {
+	fmt.Fprintf(b, "\n.. mermaid::\n\n   %s\n", rstIndent(d.Mermaid(), 3))
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRstEscape(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain text", "plain text"},
+		{"*emphasis* and `code`", `\*emphasis\* and \` + "`code\\`"},
+		{`a\b|c`, `a\\b\|c`},
+		{"subnet_ids", "subnet_ids"},
+		{"name_ ends", `name\_ ends`},
+		{"trailing_", `trailing\_`},
+	}
+	for _, tt := range tests {
+		if got := rstEscape(tt.in); got != tt.want {
+			t.Errorf("rstEscape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRstRender(t *testing.T) {
+	var b bytes.Buffer
+	if err := renderers["rst"].Render(&b, sampleDocument()); err != nil {
+		t.Fatal(err)
+	}
+	want := `==============
+my\_\*module\*
+==============
+
+.. _usage-notes:
+
+\` + "`Usage\\`" + ` \*notes\*
+-------------------
+
+* ` + "`🚀 Launch <id-launch_>`_" + `
+* ` + "`Input <input_>`_" + `
+
+.. _id-launch:
+
+🚀 Launch
+---------
+
+Run ` + "``terraform apply``" + `.
+
+.. _input:
+
+Input
+-----
+
+.. list-table::
+   :header-rows: 1
+
+   * - Name
+     - Required
+   * - ` + "``region``" + `
+     - yes
+   * - a\|b
+     - ` + "``{" + `
+         a = 1
+       ` + "}``" + `
+
+.. _id-2024-notes:
+
+2024 notes
+~~~~~~~~~~
+
+* **Strong**
+* ` + "`docs <https://example.com>`__" + `
+
+.. code-block:: hcl
+
+   x = 1
+`
+	if got := b.String(); got != want {
+		t.Errorf("rst = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// orderedObject is a JSON object that keeps its keys in insertion order.
+type orderedObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newObject() *orderedObject {
+	return &orderedObject{values: map[string]interface{}{}}
+}
+
+// Set sets key to v, keeping the original position of existing keys.
+func (o *orderedObject) Set(key string, v interface{}) *orderedObject {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = v
+	return o
+}
+
+// Get returns the value of key.
+func (o *orderedObject) Get(key string) (interface{}, bool) {
+	v, ok := o.values[key]
+	return v, ok
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *orderedObject) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("{")
+	for i, k := range o.keys {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		key, _ := json.Marshal(k)
+		val, err := json.Marshal(o.values[k])
+		if err != nil {
+			return nil, errors.Wrapf(err, "marshal %s", k)
+		}
+		b.Write(key)
+		b.WriteString(":")
+		b.Write(val)
+	}
+	b.WriteString("}")
+	return b.Bytes(), nil
+}
+
+// typeSchema translates a type constraint into a JSON Schema.
+func typeSchema(t *TypeExpr) *orderedObject {
+	s := newObject()
+	switch t.Kind {
+	case "string", "number":
+		s.Set("type", t.Kind)
+	case "bool":
+		s.Set("type", "boolean")
+	case "list", "set":
+		s.Set("type", "array").Set("items", typeSchema(t.Elem))
+		if t.Kind == "set" {
+			s.Set("uniqueItems", true)
+		}
+	case "map":
+		s.Set("type", "object").Set("additionalProperties", typeSchema(t.Elem))
+	case "tuple":
+		items := make([]interface{}, 0, len(t.Elems))
+		for _, e := range t.Elems {
+			items = append(items, typeSchema(e))
+		}
+		s.Set("type", "array").Set("prefixItems", items).
+			Set("minItems", len(items)).Set("maxItems", len(items))
+	case "object":
+		props := newObject()
+		required := []string{}
+		for _, a := range t.Attrs {
+			p := typeSchema(a.Type)
+			if a.Default != nil {
+				p.Set("default", a.Default)
+			}
+			props.Set(a.Name, p)
+			if !a.Optional {
+				required = append(required, a.Name)
+			}
+		}
+		s.Set("type", "object").Set("properties", props).
+			Set("required", required).Set("additionalProperties", false)
+	}
+	return s
+}
+
+// Patterns of the validation conditions that translate to JSON Schema
+// keywords. %s is replaced by the quoted variable reference.
+var (
+	lengthCond   = `^length\(\s*%s\s*\)\s*(<=|>=|<|>|==)\s*(\d+)$`
+	rangeCond    = `^%s\s*(<=|>=|<|>)\s*(-?[0-9.]+)$`
+	containsCond = `^contains\(\s*(\[.*\])\s*,\s*%s\s*\)$`
+	regexCond    = `^(?:can\(\s*)?regex\(\s*("(?:[^"\\]|\\.)*")\s*,\s*%s\s*\)\s*\)?$`
+)
+
+// applyValidation adds the keywords of the simple clauses of a validation
+// condition to a schema. Clauses it does not understand are skipped.
+func applyValidation(s *orderedObject, name string, v Validation) {
+	ref := regexp.QuoteMeta("var." + name)
+	match := func(pattern, clause string) []string {
+		return regexp.MustCompile(strings.Replace(pattern, "%s", ref, 1)).FindStringSubmatch(clause)
+	}
+	typ, _ := s.Get("type")
+
+	for _, clause := range splitTopLevel(unwrapExpr(v.Condition), "&&") {
+		if m := match(lengthCond, clause); m != nil {
+			n, _ := strconv.Atoi(m[2])
+			min, max := "minLength", "maxLength"
+			switch typ {
+			case "array":
+				min, max = "minItems", "maxItems"
+			case "object":
+				min, max = "minProperties", "maxProperties"
+			}
+			switch m[1] {
+			case "<=":
+				s.Set(max, n)
+			case "<":
+				s.Set(max, n-1)
+			case ">=":
+				s.Set(min, n)
+			case ">":
+				s.Set(min, n+1)
+			case "==":
+				s.Set(min, n).Set(max, n)
+			}
+		} else if m := match(rangeCond, clause); m != nil {
+			n, err := strconv.ParseFloat(m[2], 64)
+			if err != nil {
+				continue
+			}
+			keyword := map[string]string{
+				"<=": "maximum", "<": "exclusiveMaximum",
+				">=": "minimum", ">": "exclusiveMinimum",
+			}[m[1]]
+			s.Set(keyword, n)
+		} else if m := match(containsCond, clause); m != nil {
+			if values, err := parseValue(m[1]); err == nil {
+				s.Set("enum", values)
+			}
+		} else if m := match(regexCond, clause); m != nil {
+			if pattern, err := strconv.Unquote(m[1]); err == nil {
+				s.Set("pattern", pattern)
+			}
+		}
+	}
+}
+
+// variableSchema translates a variable into the JSON Schema of its value.
+func variableSchema(v HCLVar) (*orderedObject, error) {
+	t, err := parseType(v.VarType)
+	if err != nil {
+		return nil, err
+	}
+	s := typeSchema(t)
+	for _, val := range v.Validations {
+		applyValidation(s, v.Name, val)
+	}
+	// Null selects the default of a non-nullable variable.
+	if typ, ok := s.Get("type"); ok && (v.Nullable || !v.Required) {
+		s.Set("type", []interface{}{typ, "null"})
+	}
+	if v.Description != "" {
+		s.Set("description", v.Description)
+	}
+	if !v.Required && !v.Sensitive {
+		s.Set("default", v.Default)
+	}
+	if v.Sensitive {
+		s.Set("writeOnly", true)
+	}
+	return s, nil
+}
+
+// moduleSchema builds the JSON Schema of a module's input object.
+func moduleSchema(title string, vars []HCLVar) (*orderedObject, error) {
+	props := newObject()
+	required := []string{}
+	for _, v := range vars {
+		s, err := variableSchema(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "variable %q", v.Name)
+		}
+		props.Set(v.Name, s)
+		if v.Required {
+			required = append(required, v.Name)
+		}
+	}
+	return newObject().
+		Set("$schema", "https://json-schema.org/draft/2020-12/schema").
+		Set("title", title).
+		Set("type", "object").
+		Set("properties", props).
+		Set("required", required).
+		Set("additionalProperties", false), nil
+}
+
+// runSchema implements the schema command.
+func runSchema(args []string) {
+	fs := flag.NewFlagSet("schema", flag.ExitOnError)
+	variablesFile := fs.String("variables", "", "path to variables file, instead of reading the module directory")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: tfreadme schema [flags] [module-dir]\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+
+	title, err := moduleTitle(dir)
+	if err != nil {
+		log.Fatalf("Error building title: %s.", err)
+	}
+	schema, err := moduleSchema(title+" inputs", loadInputs(dir, *variablesFile))
+	if err != nil {
+		log.Fatalf("Error building schema: %s.", err)
+	}
+	raw, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		log.Fatalf("Error encoding schema: %s.", err)
+	}
+	if _, err := os.Stdout.Write(append(raw, '\n')); err != nil {
+		log.Fatalf("Error writing schema: %s.", err)
+	}
+}
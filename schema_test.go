@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestTypeSchema(t *testing.T) {
+	tests := []struct {
+		typ, want string
+	}{
+		{"string", `{"type":"string"}`},
+		{"number", `{"type":"number"}`},
+		{"bool", `{"type":"boolean"}`},
+		{"any", `{}`},
+		{"list(string)", `{"type":"array","items":{"type":"string"}}`},
+		{"set(number)", `{"type":"array","items":{"type":"number"},"uniqueItems":true}`},
+		{"map(bool)", `{"type":"object","additionalProperties":{"type":"boolean"}}`},
+		{"tuple([string, number])", `{"type":"array","prefixItems":[{"type":"string"},{"type":"number"}],"minItems":2,"maxItems":2}`},
+		{
+			`object({ name = string, size = optional(number, 2) })`,
+			`{"type":"object","properties":{"name":{"type":"string"},"size":{"type":"number","default":2}},"required":["name"],"additionalProperties":false}`,
+		},
+	}
+	for _, tt := range tests {
+		typ, err := parseType(tt.typ)
+		if err != nil {
+			t.Fatalf("parseType(%q): %s", tt.typ, err)
+		}
+		raw, err := json.Marshal(typeSchema(typ))
+		if err != nil {
+			t.Fatalf("marshal %q: %s", tt.typ, err)
+		}
+		if got := string(raw); got != tt.want {
+			t.Errorf("typeSchema(%s) = %s, want %s", tt.typ, got, tt.want)
+		}
+	}
+}
+
+func TestApplyValidation(t *testing.T) {
+	tests := []struct {
+		typ, condition, want string
+	}{
+		{"string", `length(var.v) <= 10`, `{"type":"string","maxLength":10}`},
+		{"string", `length(var.v) > 0 && length(var.v) < 64`, `{"type":"string","minLength":1,"maxLength":63}`},
+		{"list(string)", `length(var.v) == 2`, `{"type":"array","items":{"type":"string"},"minItems":2,"maxItems":2}`},
+		{"map(string)", `length(var.v) >= 1`, `{"type":"object","additionalProperties":{"type":"string"},"minProperties":1}`},
+		{"number", `var.v >= 1 && var.v < 100`, `{"type":"number","minimum":1,"exclusiveMaximum":100}`},
+		{"number", `var.v > -1.5`, `{"type":"number","exclusiveMinimum":-1.5}`},
+		{"string", `contains(["dev", "prod"], var.v)`, `{"type":"string","enum":["dev","prod"]}`},
+		{"string", `can(regex("^[a-z]+$", var.v))`, `{"type":"string","pattern":"^[a-z]+$"}`},
+		{"string", `${length(var.v) <= 5}`, `{"type":"string","maxLength":5}`},
+		{"string", `length(var.v) <= 5 || var.v == ""`, `{"type":"string"}`},
+		{"string", `length(var.other) <= 5`, `{"type":"string"}`},
+		{"string", `startswith(var.v, "a")`, `{"type":"string"}`},
+	}
+	for _, tt := range tests {
+		typ, err := parseType(tt.typ)
+		if err != nil {
+			t.Fatalf("parseType(%q): %s", tt.typ, err)
+		}
+		s := typeSchema(typ)
+		applyValidation(s, "v", Validation{Condition: tt.condition})
+		raw, err := json.Marshal(s)
+		if err != nil {
+			t.Fatalf("marshal %q: %s", tt.condition, err)
+		}
+		if got := string(raw); got != tt.want {
+			t.Errorf("applyValidation(%s) = %s, want %s", tt.condition, got, tt.want)
+		}
+	}
+}
+
+func TestVariableSchema(t *testing.T) {
+	tests := []struct {
+		v    HCLVar
+		want string
+	}{
+		{HCLVar{Name: "a", VarType: "string", Required: true}, `{"type":"string"}`},
+		{HCLVar{Name: "a", VarType: "string", Required: true, Nullable: true}, `{"type":["string","null"]}`},
+		{HCLVar{Name: "a", VarType: "number", Default: int64(3), Description: "Size."}, `{"type":["number","null"],"description":"Size.","default":3}`},
+		{HCLVar{Name: "a", VarType: "string", Default: "x", Sensitive: true}, `{"type":["string","null"],"writeOnly":true}`},
+		{HCLVar{Name: "a", Default: nil}, `{"default":null}`},
+	}
+	for _, tt := range tests {
+		s, err := variableSchema(tt.v)
+		if err != nil {
+			t.Fatalf("variableSchema(%+v): %s", tt.v, err)
+		}
+		raw, err := json.Marshal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(raw); got != tt.want {
+			t.Errorf("variableSchema(%+v) = %s, want %s", tt.v, got, tt.want)
+		}
+	}
+
+	if _, err := variableSchema(HCLVar{Name: "a", VarType: "list("}); err == nil {
+		t.Error("variableSchema with an invalid type succeeded")
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// redacted replaces sensitive values in generated documentation.
+const redacted = "<sensitive>"
+
+// displayDefault returns the default of a variable as shown in generated
+// documentation, redacted for sensitive variables.
+func displayDefault(v HCLVar) string {
+	if v.Sensitive && v.DefaultVal != "" {
+		return redacted
+	}
+	return v.DefaultVal
+}
+
+// sensitiveAttr matches resource attribute names that usually hold secrets.
+var sensitiveAttr = regexp.MustCompile(`(?i)(password|passwd|secret|token|private_key|api_key|access_key|credential)`)
+
+// credentialPatterns match string values that look like credentials.
+var credentialPatterns = []struct {
+	Name    string
+	Pattern *regexp.Regexp
+}{
+	{"AWS access key ID", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"AWS secret access key", regexp.MustCompile(`(?i)aws.{0,20}['"]?[A-Za-z0-9/+=]{40}\b`)},
+	{"private key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY( BLOCK)?-----`)},
+	{"GitHub token", regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36}\b`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+	{"JSON web token", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)},
+}
+
+// credentialKind returns the kind of credential a value contains, looking
+// into lists and objects, or "" if it contains none.
+func credentialKind(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		for _, p := range credentialPatterns {
+			if p.Pattern.MatchString(v) {
+				return p.Name
+			}
+		}
+	case []interface{}:
+		for _, e := range v {
+			if kind := credentialKind(e); kind != "" {
+				return kind
+			}
+		}
+	case map[string]interface{}:
+		for _, e := range v {
+			if kind := credentialKind(e); kind != "" {
+				return kind
+			}
+		}
+	}
+	return ""
+}
+
+// sensitiveRef reports whether a reference reads a sensitive value: a
+// sensitive input, a sensitive output of a called local module, a local
+// derived from one, or a resource attribute with a secret-looking name.
+func sensitiveRef(ref Reference, tainted map[string]bool, called map[string]*Module) bool {
+	subject := ref.Subject()
+	if subject == "" {
+		return false
+	}
+	if tainted[subject] {
+		return true
+	}
+	parts := splitTraversal(ref.Traversal)
+	switch parts[0] {
+	case "var", "local":
+		return false
+	case "module":
+		if m, ok := called[parts[1]]; ok && len(parts) > 2 {
+			for _, o := range m.Outputs {
+				if o.Name == parts[2] {
+					return o.Sensitive
+				}
+			}
+		}
+		return false
+	}
+	// Attributes follow the resource address.
+	attrs := parts[len(strings.Split(subject, ".")):]
+	for _, a := range attrs {
+		if sensitiveAttr.MatchString(a) {
+			return true
+		}
+	}
+	return false
+}
+
+// taintedValues returns the addresses of sensitive inputs and of the locals
+// that derive from sensitive values.
+func taintedValues(m *Module, called map[string]*Module) map[string]bool {
+	tainted := map[string]bool{}
+	for _, v := range m.Inputs {
+		if v.Sensitive {
+			tainted["var."+v.Name] = true
+		}
+	}
+	// Locals may reference each other in any order; iterate to a fixed point.
+	for changed := true; changed; {
+		changed = false
+		for _, l := range m.Locals {
+			if tainted["local."+l.Name] {
+				continue
+			}
+			for _, ref := range l.Refs {
+				if sensitiveRef(ref, tainted, called) {
+					tainted["local."+l.Name], changed = true, true
+					break
+				}
+			}
+		}
+	}
+	return tainted
+}
+
+// checkSensitiveOutputs reports outputs that expose sensitive values
+// without being marked sensitive.
+func checkSensitiveOutputs(c *lintContext) []Issue {
+	tainted := taintedValues(c.Module, c.Called)
+	var issues []Issue
+	for _, o := range c.Module.Outputs {
+		if o.Sensitive {
+			continue
+		}
+		for _, ref := range o.Refs {
+			if sensitiveRef(ref, tainted, c.Called) {
+				issues = append(issues, c.issue("sensitive-output", ref.Pos, "output %q exposes sensitive value %s but is not marked sensitive", o.Name, ref.Traversal))
+				break
+			}
+		}
+	}
+	return issues
+}
+
+// checkCredentialDefaults reports variable defaults that look like
+// credentials.
+func checkCredentialDefaults(c *lintContext) []Issue {
+	var issues []Issue
+	for _, v := range c.Module.Inputs {
+		if kind := credentialKind(v.Default); kind != "" {
+			issues = append(issues, c.issue("credential-default", v.Pos, "default of variable %q looks like a credential (%s)", v.Name, kind))
+		}
+	}
+	return issues
+}
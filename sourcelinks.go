@@ -0,0 +1,53 @@
+package main
+
+import (
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+// sourceLinker returns the link to a position of the module, or "" if the
+// position is unknown.
+type sourceLinker func(pos hcl.Range) string
+
+// relativeLinks links to declarations relative to a README in the module
+// directory, e.g. "variables.tf#L12".
+func relativeLinks(pos hcl.Range) string {
+	if pos.Start.Line == 0 {
+		return ""
+	}
+	return filepath.ToSlash(pos.Filename) + "#L" + strconv.Itoa(pos.Start.Line)
+}
+
+// templateLinks links to declarations through a repository web URL
+// template, in which {ref}, {path} and {line} are replaced by the git
+// revision, the path of the file in the repository and the line, e.g.
+// "https://github.com/org/repo/blob/{ref}/{path}#L{line}". Without a ref,
+// the commit checked out is used.
+func templateLinks(template, ref, dir string) (sourceLinker, error) {
+	if ref == "" {
+		out, err := git("rev-parse", "HEAD")
+		if err != nil {
+			return nil, err
+		}
+		ref = strings.TrimSpace(string(out))
+	}
+	prefix, err := gitSource{Ref: ref, Dir: dir}.treePath()
+	if err != nil {
+		return nil, err
+	}
+	return func(pos hcl.Range) string {
+		if pos.Start.Line == 0 {
+			return ""
+		}
+		r := strings.NewReplacer(
+			"{ref}", ref,
+			"{path}", path.Join(prefix, filepath.ToSlash(pos.Filename)),
+			"{line}", strconv.Itoa(pos.Start.Line),
+		)
+		return r.Replace(template)
+	}, nil
+}
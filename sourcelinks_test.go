@@ -0,0 +1,54 @@
+package main
+
+import (
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestRelativeLinks(t *testing.T) {
+	tests := []struct {
+		pos  hcl.Range
+		want string
+	}{
+		{hcl.Range{Filename: "variables.tf", Start: hcl.Pos{Line: 12}}, "variables.tf#L12"},
+		{hcl.Range{Filename: "network/main.tf", Start: hcl.Pos{Line: 1}}, "network/main.tf#L1"},
+		{hcl.Range{Filename: "variables.tf"}, ""},
+		{hcl.Range{}, ""},
+	}
+	for _, tt := range tests {
+		if got := relativeLinks(tt.pos); got != tt.want {
+			t.Errorf("relativeLinks(%s) = %q, want %q", tt.pos, got, tt.want)
+		}
+	}
+}
+
+func TestTemplateLinks(t *testing.T) {
+	out, err := git("rev-parse", "--show-prefix")
+	if err != nil {
+		t.Skipf("not in a git work tree: %s", err)
+	}
+	prefix := strings.TrimSpace(string(out))
+	link, err := templateLinks("https://example.com/repo/blob/{ref}/{path}#L{line}", "v1.2.0", "example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		pos  hcl.Range
+		want string
+	}{
+		{hcl.Range{Filename: "variables.tf", Start: hcl.Pos{Line: 12}}, "https://example.com/repo/blob/v1.2.0/" + path.Join(prefix, "example/variables.tf") + "#L12"},
+		{hcl.Range{Filename: "variables.tf"}, ""},
+	}
+	for _, tt := range tests {
+		if got := link(tt.pos); got != tt.want {
+			t.Errorf("link(%s) = %q, want %q", tt.pos, got, tt.want)
+		}
+	}
+
+	if _, err := templateLinks("{path}", "v1.2.0", "../outside"); err == nil {
+		t.Error("templateLinks outside the repository succeeded")
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// stateModule is a module of the values section of a state in the JSON
+// written by `terraform show -json`.
+type stateModule struct {
+	Resources []struct {
+		Mode string `json:"mode"`
+		Type string `json:"type"`
+	} `json:"resources"`
+	ChildModules []stateModule `json:"child_modules"`
+}
+
+// stateOutput is an output value of a deployment.
+type stateOutput struct {
+	Name      string
+	Value     interface{}
+	Sensitive bool
+}
+
+// deployment is what a state records about a deployed root module.
+type deployment struct {
+	Outputs []stateOutput
+	// Resources counts the managed resources of each type, in all modules.
+	Resources map[string]int
+}
+
+// readState reads the JSON written by `terraform show -json` for a state.
+func readState(path string) (*deployment, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "read file")
+	}
+	var state struct {
+		Values *struct {
+			Outputs map[string]struct {
+				Value     interface{} `json:"value"`
+				Sensitive bool        `json:"sensitive"`
+			} `json:"outputs"`
+			RootModule stateModule `json:"root_module"`
+		} `json:"values"`
+	}
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return nil, errors.Wrap(err, "parse")
+	}
+	if state.Values == nil {
+		return nil, errors.New("no values section, expected the output of terraform show -json")
+	}
+
+	d := &deployment{Resources: map[string]int{}}
+	names := make([]string, 0, len(state.Values.Outputs))
+	for name := range state.Values.Outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		o := state.Values.Outputs[name]
+		d.Outputs = append(d.Outputs, stateOutput{Name: name, Value: jsonValue(o.Value), Sensitive: o.Sensitive})
+	}
+	d.count(state.Values.RootModule)
+	return d, nil
+}
+
+func (d *deployment) count(m stateModule) {
+	for _, r := range m.Resources {
+		if r.Mode == "managed" {
+			d.Resources[r.Type]++
+		}
+	}
+	for _, child := range m.ChildModules {
+		d.count(child)
+	}
+}
+
+// deploymentSections returns the Current Outputs and Deployed Resources
+// sections of a deployment. Sensitive values are redacted.
+func deploymentSections(d *deployment) []*Section {
+	outputs := &Table{Columns: []Column{{Title: "Name"}, {Title: "Value"}}}
+	for _, o := range d.Outputs {
+		value := formatValue(o.Value)
+		if o.Sensitive {
+			value = redacted
+		}
+		outputs.Rows = append(outputs.Rows, Row{Cells: []Cell{plain(o.Name), code(value)}})
+	}
+
+	types := make([]string, 0, len(d.Resources))
+	for t := range d.Resources {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	resources := &Table{Columns: []Column{{Title: "Type"}, {Title: "Count", Align: AlignCenter}}}
+	for _, t := range types {
+		resources.Rows = append(resources.Rows, Row{Cells: []Cell{code(t), plain(strconv.Itoa(d.Resources[t]))}})
+	}
+
+	return []*Section{
+		{Title: "Current Outputs", Blocks: []Block{outputs}},
+		{Title: "Deployed Resources", Blocks: []Block{resources}},
+	}
+}
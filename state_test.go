@@ -0,0 +1,93 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadState(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"state.json": `{
+  "format_version": "1.0",
+  "values": {
+    "outputs": {
+      "vpc_id": {"value": "vpc-123", "type": "string"},
+      "password": {"value": "hunter2", "sensitive": true},
+      "zones": {"value": ["a", "b"]},
+      "size": {"value": 3}
+    },
+    "root_module": {
+      "resources": [
+        {"mode": "managed", "type": "aws_vpc"},
+        {"mode": "data", "type": "aws_ami"},
+        {"mode": "managed", "type": "aws_subnet"}
+      ],
+      "child_modules": [
+        {
+          "resources": [{"mode": "managed", "type": "aws_subnet"}],
+          "child_modules": [{"resources": [{"mode": "managed", "type": "aws_route"}]}]
+        }
+      ]
+    }
+  }
+}`,
+		"empty.json": `{"format_version": "1.0"}`,
+	})
+
+	d, err := readState(filepath.Join(dir, "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &deployment{
+		Outputs: []stateOutput{
+			{Name: "password", Value: "hunter2", Sensitive: true},
+			{Name: "size", Value: int64(3)},
+			{Name: "vpc_id", Value: "vpc-123"},
+			{Name: "zones", Value: []interface{}{"a", "b"}},
+		},
+		Resources: map[string]int{"aws_vpc": 1, "aws_subnet": 2, "aws_route": 1},
+	}
+	if !reflect.DeepEqual(d, want) {
+		t.Errorf("readState = %+v, want %+v", d, want)
+	}
+
+	if _, err := readState(filepath.Join(dir, "empty.json")); err == nil {
+		t.Error("readState without values succeeded")
+	}
+}
+
+func TestDeploymentSections(t *testing.T) {
+	d := &deployment{
+		Outputs: []stateOutput{
+			{Name: "password", Value: "hunter2", Sensitive: true},
+			{Name: "vpc_id", Value: "vpc-123"},
+		},
+		Resources: map[string]int{"aws_vpc": 1, "aws_subnet": 2},
+	}
+	sections := deploymentSections(d)
+	var titles []string
+	var rows [][]string
+	for _, s := range sections {
+		titles = append(titles, s.Title)
+		for _, r := range s.Blocks[0].(*Table).Rows {
+			var cells []string
+			for _, c := range r.Cells {
+				cells = append(cells, c[0].Value)
+			}
+			rows = append(rows, cells)
+		}
+	}
+	if want := []string{"Current Outputs", "Deployed Resources"}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("titles = %q, want %q", titles, want)
+	}
+	want := [][]string{
+		{"password", redacted},
+		{"vpc_id", `"vpc-123"`},
+		{"aws_subnet", "2"},
+		{"aws_vpc", "1"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q, want %q", rows, want)
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// placeholder returns a zero value matching a variable's type constraint,
+// used for required inputs in a generated tfvars file.
+func placeholder(v HCLVar) interface{} {
+	t, err := parseType(v.VarType)
+	if err != nil {
+		return ""
+	}
+	return typePlaceholder(t)
+}
+
+func typePlaceholder(t *TypeExpr) interface{} {
+	switch t.Kind {
+	case "number":
+		return int64(0)
+	case "bool":
+		return false
+	case "list", "set":
+		return []interface{}{}
+	case "tuple":
+		elems := make([]interface{}, 0, len(t.Elems))
+		for _, e := range t.Elems {
+			elems = append(elems, typePlaceholder(e))
+		}
+		return elems
+	case "map":
+		return map[string]interface{}{}
+	case "object":
+		attrs := map[string]interface{}{}
+		for _, a := range t.Attrs {
+			if !a.Optional {
+				attrs[a.Name] = typePlaceholder(a.Type)
+			}
+		}
+		return attrs
+	}
+	return ""
+}
+
+// commentLines prefixes every line of s with "# ".
+func commentLines(s string) string {
+	return "# " + strings.Replace(s, "\n", "\n# ", -1)
+}
+
+// writeTfvarsHCL writes a tfvars skeleton in HCL syntax. Required inputs are
+// set to a placeholder, optional ones are commented out with their default,
+// which is redacted for sensitive inputs.
+func writeTfvarsHCL(w io.Writer, vars []HCLVar) error {
+	var b bytes.Buffer
+	for i, v := range vars {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		if v.Description != "" {
+			fmt.Fprintf(&b, "%s\n", commentLines(v.Description))
+		}
+		if v.VarType != "" {
+			fmt.Fprintf(&b, "# Type: %s\n", v.VarType)
+		}
+		if v.Sensitive {
+			b.WriteString("# Sensitive: do not commit real values.\n")
+		}
+		if v.Required {
+			fmt.Fprintf(&b, "# Required.\n%s = %s\n", v.Name, formatValue(placeholder(v)))
+			continue
+		}
+		def := formatValue(v.Default)
+		if v.Sensitive {
+			def = redacted
+		}
+		fmt.Fprintf(&b, "%s\n", commentLines(v.Name+" = "+def))
+	}
+	_, err := b.WriteTo(w)
+	return errors.Wrap(err, "write stream")
+}
+
+// writeTfvarsJSON writes a tfvars skeleton in JSON syntax. JSON has no
+// comments, so optional inputs are set to their default. Sensitive optional
+// inputs are left out, so that their default applies without being written.
+func writeTfvarsJSON(w io.Writer, vars []HCLVar) error {
+	var b bytes.Buffer
+	b.WriteString("{")
+	n := 0
+	for _, v := range vars {
+		value := v.Default
+		if v.Required {
+			value = placeholder(v)
+		} else if v.Sensitive {
+			continue
+		}
+		raw, err := json.MarshalIndent(value, "  ", "  ")
+		if err != nil {
+			return errors.Wrapf(err, "marshal %s", v.Name)
+		}
+		if n > 0 {
+			b.WriteString(",")
+		}
+		n++
+		fmt.Fprintf(&b, "\n  %q: %s", v.Name, raw)
+	}
+	b.WriteString("\n}\n")
+	_, err := b.WriteTo(w)
+	return errors.Wrap(err, "write stream")
+}
+
+// runTfvars implements the tfvars command.
+func runTfvars(args []string) {
+	fs := flag.NewFlagSet("tfvars", flag.ExitOnError)
+	var (
+		variablesFile = fs.String("variables", "", "path to variables file, instead of reading the module directory")
+		format        = fs.String("format", "hcl", "output format: hcl or json")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: tfreadme tfvars [flags] [module-dir]\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+
+	write := writeTfvarsHCL
+	switch *format {
+	case "hcl":
+	case "json":
+		write = writeTfvarsJSON
+	default:
+		log.Fatalf("Unknown tfvars format %q, expected hcl or json.", *format)
+	}
+
+	if err := write(os.Stdout, loadInputs(dir, *variablesFile)); err != nil {
+		log.Fatalf("Error writing tfvars: %s.", err)
+	}
+}
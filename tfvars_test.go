@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestPlaceholder(t *testing.T) {
+	tests := []struct {
+		typ  string
+		want interface{}
+	}{
+		{"", ""},
+		{"string", ""},
+		{"number", int64(0)},
+		{"bool", false},
+		{"any", ""},
+		{"list(string)", []interface{}{}},
+		{"set(number)", []interface{}{}},
+		{"map(string)", map[string]interface{}{}},
+		{"tuple([string, number, bool])", []interface{}{"", int64(0), false}},
+		{"object({ name = string, size = optional(number), tags = map(string) })", map[string]interface{}{"name": "", "tags": map[string]interface{}{}}},
+		{"list(", ""},
+	}
+	for _, tt := range tests {
+		if got := placeholder(HCLVar{VarType: tt.typ}); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("placeholder(%q) = %#v, want %#v", tt.typ, got, tt.want)
+		}
+	}
+}
+
+// tfvarsInputs are inputs of each kind written by the tfvars command.
+var tfvarsInputs = []HCLVar{
+	{Name: "region", VarType: "string", Description: "AWS region.\nUsed for all resources.", Required: true},
+	{Name: "zones", VarType: "list(string)", Required: true},
+	{Name: "size", VarType: "number", Default: int64(2), DefaultVal: "2"},
+	{Name: "tags", Default: map[string]interface{}{"team": "infra"}, DefaultVal: `{ team = "infra" }`},
+	{Name: "password", VarType: "string", Sensitive: true, Default: "hunter2", DefaultVal: `"hunter2"`},
+	{Name: "token", Sensitive: true, Required: true},
+}
+
+func TestWriteTfvarsHCL(t *testing.T) {
+	var b bytes.Buffer
+	if err := writeTfvarsHCL(&b, tfvarsInputs); err != nil {
+		t.Fatal(err)
+	}
+	want := `# AWS region.
+# Used for all resources.
+# Type: string
+# Required.
+region = ""
+
+# Type: list(string)
+# Required.
+zones = []
+
+# Type: number
+# size = 2
+
+# tags = {
+#   team = "infra"
+# }
+
+# Type: string
+# Sensitive: do not commit real values.
+# password = <sensitive>
+
+# Sensitive: do not commit real values.
+# Required.
+token = ""
+`
+	if got := b.String(); got != want {
+		t.Errorf("writeTfvarsHCL = %s, want %s", got, want)
+	}
+}
+
+func TestWriteTfvarsJSON(t *testing.T) {
+	var b bytes.Buffer
+	if err := writeTfvarsJSON(&b, tfvarsInputs); err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "region": "",
+  "zones": [],
+  "size": 2,
+  "tags": {
+    "team": "infra"
+  },
+  "token": ""
+}
+`
+	if got := b.String(); got != want {
+		t.Errorf("writeTfvarsJSON = %s, want %s", got, want)
+	}
+
+	b.Reset()
+	if err := writeTfvarsJSON(&b, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := b.String(), "{\n}\n"; got != want {
+		t.Errorf("writeTfvarsJSON(nil) = %q, want %q", got, want)
+	}
+}
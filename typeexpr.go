@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/pkg/errors"
+)
+
+// TypeExpr is a parsed Terraform type constraint such as
+// `map(object({ name = string, port = optional(number, 80) }))`.
+type TypeExpr struct {
+	// Kind is one of string, number, bool, any, list, set, map, tuple or
+	// object.
+	Kind string
+	// Elem is the element type of a list, set or map.
+	Elem *TypeExpr
+	// Elems are the element types of a tuple.
+	Elems []*TypeExpr
+	// Attrs are the attributes of an object, in declaration order.
+	Attrs []TypeAttr
+}
+
+// TypeAttr is an attribute of an object type.
+type TypeAttr struct {
+	Name     string
+	Type     *TypeExpr
+	Optional bool
+	// Default is the decoded default of an optional attribute, nil if unset.
+	Default interface{}
+}
+
+// anyType is the constraint of variables without a type.
+var anyType = &TypeExpr{Kind: "any"}
+
+// String formats the type back into Terraform syntax.
+func (t *TypeExpr) String() string {
+	switch t.Kind {
+	case "list", "set", "map":
+		return t.Kind + "(" + t.Elem.String() + ")"
+	case "tuple":
+		elems := make([]string, 0, len(t.Elems))
+		for _, e := range t.Elems {
+			elems = append(elems, e.String())
+		}
+		return "tuple([" + strings.Join(elems, ", ") + "])"
+	case "object":
+		attrs := make([]string, 0, len(t.Attrs))
+		for _, a := range t.Attrs {
+			s := a.Type.String()
+			if a.Optional {
+				if a.Default != nil {
+					s = "optional(" + s + ", " + formatValue(a.Default) + ")"
+				} else {
+					s = "optional(" + s + ")"
+				}
+			}
+			attrs = append(attrs, a.Name+" = "+s)
+		}
+		return "object({" + strings.Join(attrs, ", ") + "})"
+	}
+	return t.Kind
+}
+
+// parseType parses a type constraint. An empty constraint is `any`, and
+// the Terraform 0.11 names "list" and "map" stand for list(any) and
+// map(any).
+func parseType(s string) (*TypeExpr, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return anyType, nil
+	}
+	expr, diags := hclsyntax.ParseExpression([]byte(s), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, errors.Wrapf(diags, "type %q", s)
+	}
+	return typeExpr(expr)
+}
+
+// typeExpr decodes a type constraint from its expression in the syntax
+// tree, e.g. the value of the type attribute of a variable.
+func typeExpr(expr hcl.Expression) (*TypeExpr, error) {
+	switch expr := expr.(type) {
+	case *hclsyntax.ScopeTraversalExpr:
+		if len(expr.Traversal) == 1 {
+			switch kind := expr.Traversal.RootName(); kind {
+			case "string", "number", "bool", "any":
+				return &TypeExpr{Kind: kind}, nil
+			case "list", "map":
+				return &TypeExpr{Kind: kind, Elem: anyType}, nil
+			}
+		}
+	case *hclsyntax.FunctionCallExpr:
+		if len(expr.Args) != 1 {
+			return nil, typeErrorf(expr, "%s takes one argument", expr.Name)
+		}
+		arg := expr.Args[0]
+		switch expr.Name {
+		case "list", "set", "map":
+			elem, err := typeExpr(arg)
+			if err != nil {
+				return nil, err
+			}
+			return &TypeExpr{Kind: expr.Name, Elem: elem}, nil
+		case "tuple":
+			return tupleType(arg)
+		case "object":
+			return objectType(arg)
+		}
+	}
+	return nil, typeErrorf(expr, "invalid type constraint")
+}
+
+func typeErrorf(expr hcl.Expression, format string, args ...interface{}) error {
+	return errors.Errorf("type at %s: %s", expr.Range(), fmt.Sprintf(format, args...))
+}
+
+// tupleType decodes the list of element types of tuple([...]).
+func tupleType(expr hcl.Expression) (*TypeExpr, error) {
+	list, ok := expr.(*hclsyntax.TupleConsExpr)
+	if !ok {
+		return nil, typeErrorf(expr, "tuple takes a list of types")
+	}
+	t := &TypeExpr{Kind: "tuple"}
+	for _, e := range list.Exprs {
+		elem, err := typeExpr(e)
+		if err != nil {
+			return nil, err
+		}
+		t.Elems = append(t.Elems, elem)
+	}
+	return t, nil
+}
+
+// objectType decodes the attribute types of object({...}).
+func objectType(expr hcl.Expression) (*TypeExpr, error) {
+	obj, ok := expr.(*hclsyntax.ObjectConsExpr)
+	if !ok {
+		return nil, typeErrorf(expr, "object takes an object of attribute types")
+	}
+	t := &TypeExpr{Kind: "object"}
+	for _, item := range obj.Items {
+		name := hcl.ExprAsKeyword(item.KeyExpr)
+		if name == "" {
+			key, err := exprValue(item.KeyExpr)
+			if s, ok := key.(string); err == nil && ok {
+				name = s
+			}
+		}
+		if name == "" {
+			return nil, typeErrorf(item.KeyExpr, "invalid attribute name")
+		}
+		attr, err := attrTypeExpr(name, item.ValueExpr)
+		if err != nil {
+			return nil, err
+		}
+		t.Attrs = append(t.Attrs, attr)
+	}
+	return t, nil
+}
+
+// attrTypeExpr decodes the type of an object attribute, which may be
+// wrapped in optional(type) or optional(type, default).
+func attrTypeExpr(name string, expr hcl.Expression) (TypeAttr, error) {
+	a := TypeAttr{Name: name}
+	call, ok := expr.(*hclsyntax.FunctionCallExpr)
+	if !ok || call.Name != "optional" {
+		t, err := typeExpr(expr)
+		a.Type = t
+		return a, err
+	}
+	if len(call.Args) < 1 || len(call.Args) > 2 {
+		return a, typeErrorf(call, "optional takes a type and a default")
+	}
+	t, err := typeExpr(call.Args[0])
+	if err != nil {
+		return a, err
+	}
+	a.Type, a.Optional = t, true
+	if len(call.Args) == 2 {
+		def, err := exprValue(call.Args[1])
+		if err != nil {
+			return a, typeErrorf(call.Args[1], "default of %q: %s", name, err)
+		}
+		a.Default = def
+	}
+	return a, nil
+}
+
+// parseValue decodes a literal HCL value such as `["a", "b"]` or `80`.
+func parseValue(raw string) (interface{}, error) {
+	expr, diags := hclsyntax.ParseExpression([]byte(raw), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, errors.Wrap(diags, "parse value")
+	}
+	return exprValue(expr)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseType(t *testing.T) {
+	tests := []struct {
+		src  string
+		want *TypeExpr
+	}{
+		{"", anyType},
+		{"string", &TypeExpr{Kind: "string"}},
+		{"any", anyType},
+		{"list", &TypeExpr{Kind: "list", Elem: anyType}},
+		{"map", &TypeExpr{Kind: "map", Elem: anyType}},
+		{"list(string)", &TypeExpr{Kind: "list", Elem: &TypeExpr{Kind: "string"}}},
+		{"set(number)", &TypeExpr{Kind: "set", Elem: &TypeExpr{Kind: "number"}}},
+		{"map(list(bool))", &TypeExpr{Kind: "map", Elem: &TypeExpr{Kind: "list", Elem: &TypeExpr{Kind: "bool"}}}},
+		{"tuple([string, number])", &TypeExpr{Kind: "tuple", Elems: []*TypeExpr{{Kind: "string"}, {Kind: "number"}}}},
+		{"tuple([])", &TypeExpr{Kind: "tuple"}},
+		{
+			"object({ name = string, port = optional(number, 80) })",
+			&TypeExpr{Kind: "object", Attrs: []TypeAttr{
+				{Name: "name", Type: &TypeExpr{Kind: "string"}},
+				{Name: "port", Type: &TypeExpr{Kind: "number"}, Optional: true, Default: int64(80)},
+			}},
+		},
+		{
+			"object({\n  tags = optional(map(string))\n  \"cidr\" = optional(list(string), [\"10.0.0.0/16\"])\n})",
+			&TypeExpr{Kind: "object", Attrs: []TypeAttr{
+				{Name: "tags", Type: &TypeExpr{Kind: "map", Elem: &TypeExpr{Kind: "string"}}, Optional: true},
+				{Name: "cidr", Type: &TypeExpr{Kind: "list", Elem: &TypeExpr{Kind: "string"}}, Optional: true, Default: []interface{}{"10.0.0.0/16"}},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		got, err := parseType(tt.src)
+		if err != nil {
+			t.Errorf("parseType(%q): %s", tt.src, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseType(%q) = %s, want %s", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestParseTypeErrors(t *testing.T) {
+	for _, src := range []string{
+		"strin",
+		"list(string",
+		"list(string, number)",
+		"tuple(string)",
+		"object(string)",
+		"object({ a = optional(string, 1, 2) })",
+		"map(var.x)",
+		`"string"`,
+	} {
+		if got, err := parseType(src); err == nil {
+			t.Errorf("parseType(%q) = %s, want an error", src, got)
+		}
+	}
+}
+
+func TestTypeString(t *testing.T) {
+	for _, src := range []string{
+		"string",
+		"list(any)",
+		"map(set(number))",
+		"tuple([string, bool])",
+		`object({name = string, port = optional(number, 80), zones = optional(list(string), ["a"])})`,
+	} {
+		typ, err := parseType(src)
+		if err != nil {
+			t.Errorf("parseType(%q): %s", src, err)
+			continue
+		}
+		if got := typ.String(); got != src {
+			t.Errorf("parseType(%q).String() = %q", src, got)
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/pkg/errors"
+)
+
+// tfvarsEntry is a variable assignment in a tfvars file.
+type tfvarsEntry struct {
+	Name string
+	Val  interface{} // Decoded value, nil for null.
+	Pos  hcl.Range
+}
+
+// tfvarsFile is a parsed tfvars file, in HCL or JSON syntax.
+type tfvarsFile struct {
+	Path    string
+	Entries []tfvarsEntry
+}
+
+// readTfvars parses a tfvars file.
+func readTfvars(path string) (*tfvarsFile, error) {
+	f, err := parseHCLFile(path)
+	if err != nil {
+		return nil, err
+	}
+	attrs, diags := f.Body.JustAttributes()
+	if diags.HasErrors() {
+		return nil, errors.Wrap(diags, "decode")
+	}
+	tf := &tfvarsFile{Path: path}
+	for _, a := range sortedAttributes(attrs) {
+		v, err := exprValue(a.Expr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "value of %q", a.Name)
+		}
+		tf.Entries = append(tf.Entries, tfvarsEntry{Name: a.Name, Val: v, Pos: a.Range})
+	}
+	return tf, nil
+}
+
+// Value returns the decoded value assigned to name and whether it is set.
+// Later assignments win.
+func (tf *tfvarsFile) Value(name string) (interface{}, bool) {
+	var (
+		v   interface{}
+		set bool
+	)
+	for _, e := range tf.Entries {
+		if e.Name == name {
+			v, set = e.Val, true
+		}
+	}
+	return v, set
+}
+
+// Issue is a problem found in a file.
+type Issue struct {
+	Path string
+	Pos  hcl.Range
+	Rule string // Name of the lint rule, if any.
+	Msg  string
+}
+
+func (i Issue) String() string {
+	msg := i.Msg
+	if i.Rule != "" {
+		msg += " (" + i.Rule + ")"
+	}
+	if i.Pos.Start.Line == 0 {
+		return fmt.Sprintf("%s: %s", i.Path, msg)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", i.Path, i.Pos.Start.Line, i.Pos.Start.Column, msg)
+}
+
+// valueKind names the kind of a decoded value for error messages.
+func valueKind(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case int64, float64:
+		return "number"
+	case bool:
+		return "bool"
+	case string:
+		return "string"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "unknown"
+}
+
+// checkType reports where a value does not conform to a type constraint,
+// following Terraform's conversion rules: primitives convert between each
+// other where the string form allows it, and extra object attributes are
+// dropped.
+func checkType(t *TypeExpr, v interface{}, name string, pos hcl.Range) []Issue {
+	mismatch := []Issue{{Pos: pos, Msg: fmt.Sprintf("%s: expected %s, got %s", name, t, valueKind(v))}}
+
+	if v == nil {
+		return nil
+	}
+	switch t.Kind {
+	case "any":
+		return nil
+	case "string":
+		switch v.(type) {
+		case string, int64, float64, bool:
+			return nil
+		}
+		return mismatch
+	case "number":
+		switch v := v.(type) {
+		case int64, float64:
+			return nil
+		case string:
+			if _, err := strconv.ParseFloat(v, 64); err == nil {
+				return nil
+			}
+		}
+		return mismatch
+	case "bool":
+		switch v := v.(type) {
+		case bool:
+			return nil
+		case string:
+			if v == "true" || v == "false" {
+				return nil
+			}
+		}
+		return mismatch
+	case "list", "set", "tuple":
+		list, ok := v.([]interface{})
+		if !ok {
+			return mismatch
+		}
+		if t.Kind == "tuple" && len(list) != len(t.Elems) {
+			return []Issue{{Pos: pos, Msg: fmt.Sprintf("%s: expected %d elements for %s, got %d", name, len(t.Elems), t, len(list))}}
+		}
+		var issues []Issue
+		for i, elem := range list {
+			et := t.Elem
+			if t.Kind == "tuple" {
+				et = t.Elems[i]
+			}
+			issues = append(issues, checkType(et, elem, fmt.Sprintf("%s[%d]", name, i), pos)...)
+		}
+		return issues
+	case "map", "object":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return mismatch
+		}
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var issues []Issue
+		for _, key := range keys {
+			if t.Kind == "map" {
+				issues = append(issues, checkType(t.Elem, obj[key], name+"."+key, pos)...)
+				continue
+			}
+			for _, a := range t.Attrs {
+				if a.Name == key {
+					issues = append(issues, checkType(a.Type, obj[key], name+"."+key, pos)...)
+				}
+			}
+		}
+		for _, a := range t.Attrs {
+			if _, ok := obj[a.Name]; !ok && !a.Optional {
+				issues = append(issues, Issue{Pos: pos, Msg: fmt.Sprintf("%s: missing required attribute %q", name, a.Name)})
+			}
+		}
+		return issues
+	}
+	return nil
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
+
+func minInt(first int, rest ...int) int {
+	for _, n := range rest {
+		if n < first {
+			first = n
+		}
+	}
+	return first
+}
+
+// suggest returns the candidate closest to name, or "" if none is close
+// enough to be a likely typo.
+func suggest(name string, candidates []string) string {
+	best, bestDist := "", len(name)/3+2
+	for _, c := range candidates {
+		if d := levenshtein(name, c); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+// validateTfvars checks tfvars files against a module's variables.
+// Required variables must be set in at least one of the files.
+func validateTfvars(vars []HCLVar, files []*tfvarsFile) []Issue {
+	declared := make(map[string]HCLVar, len(vars))
+	names := make([]string, 0, len(vars))
+	for _, v := range vars {
+		declared[v.Name] = v
+		names = append(names, v.Name)
+	}
+
+	var issues []Issue
+	set := map[string]bool{}
+	for _, f := range files {
+		for _, e := range f.Entries {
+			set[e.Name] = true
+			v, ok := declared[e.Name]
+			if !ok {
+				msg := fmt.Sprintf("variable %q is not declared by the module", e.Name)
+				if s := suggest(e.Name, names); s != "" {
+					msg += fmt.Sprintf(", did you mean %q?", s)
+				}
+				issues = append(issues, Issue{Path: f.Path, Pos: e.Pos, Msg: msg})
+				continue
+			}
+			t, err := parseType(v.VarType)
+			if err != nil {
+				issues = append(issues, Issue{Path: f.Path, Pos: e.Pos, Msg: fmt.Sprintf("%s: cannot check value: %s", e.Name, err)})
+				continue
+			}
+			// Terraform replaces null with the default of a non-nullable
+			// variable, so null is only an error without a default.
+			if e.Val == nil && !v.Nullable && v.Required {
+				issues = append(issues, Issue{Path: f.Path, Pos: e.Pos, Msg: fmt.Sprintf("%s: variable is not nullable", e.Name)})
+				continue
+			}
+			for _, issue := range checkType(t, e.Val, e.Name, e.Pos) {
+				issue.Path = f.Path
+				issues = append(issues, issue)
+			}
+		}
+	}
+
+	for _, v := range vars {
+		if v.Required && !set[v.Name] {
+			path := files[len(files)-1].Path
+			issues = append(issues, Issue{Path: path, Msg: fmt.Sprintf("missing required variable %q", v.Name)})
+		}
+	}
+	return issues
+}
+
+// runValidateTfvars implements the validate-tfvars command.
+func runValidateTfvars(args []string) {
+	fs := flag.NewFlagSet("validate-tfvars", flag.ExitOnError)
+	var (
+		dir           = fs.String("module", ".", "module `dir`ectory declaring the variables")
+		variablesFile = fs.String("variables", "", "path to variables file, instead of reading the module directory")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: tfreadme validate-tfvars [flags] file.tfvars...\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	hclVars := loadInputs(*dir, *variablesFile)
+	files := make([]*tfvarsFile, 0, fs.NArg())
+	for _, path := range fs.Args() {
+		f, err := readTfvars(path)
+		if err != nil {
+			log.Fatalf("Error reading tfvars file %q: %s.", path, err)
+		}
+		files = append(files, f)
+	}
+
+	issues := validateTfvars(hclVars, files)
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+	if len(issues) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+)
+
+func TestCheckType(t *testing.T) {
+	tests := []struct {
+		typ  string
+		val  interface{}
+		want []string
+	}{
+		{"string", "a", nil},
+		{"string", int64(1), nil},
+		{"string", []interface{}{}, []string{"v: expected string, got list"}},
+		{"number", "1.5", nil},
+		{"number", "one", []string{"v: expected number, got string"}},
+		{"bool", "true", nil},
+		{"bool", "yes", []string{"v: expected bool, got string"}},
+		{"any", map[string]interface{}{}, nil},
+		{"string", nil, nil},
+		{"list(number)", []interface{}{int64(1), "x"}, []string{"v[1]: expected number, got string"}},
+		{"tuple([string, bool])", []interface{}{"a"}, []string{"v: expected 2 elements for tuple([string, bool]), got 1"}},
+		{"map(bool)", map[string]interface{}{"b": "no", "a": true}, []string{"v.b: expected bool, got string"}},
+		{"map(string)", "x", []string{"v: expected map(string), got string"}},
+		{
+			"object({ name = string, size = optional(number) })",
+			map[string]interface{}{"size": "big", "extra": true},
+			[]string{"v.size: expected number, got string", `v: missing required attribute "name"`},
+		},
+	}
+	for _, tt := range tests {
+		typ, err := parseType(tt.typ)
+		if err != nil {
+			t.Fatalf("parseType(%q): %s", tt.typ, err)
+		}
+		var got []string
+		for _, issue := range checkType(typ, tt.val, "v", hcl.Range{}) {
+			got = append(got, issue.Msg)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("checkType(%s, %#v) = %q, want %q", tt.typ, tt.val, got, tt.want)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"region", "instance_type", "tags"}
+	tests := []struct {
+		name, want string
+	}{
+		{"regoin", "region"},
+		{"instance_typ", "instance_type"},
+		{"tag", "tags"},
+		{"vpc_id", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := suggest(tt.name, candidates); got != tt.want {
+			t.Errorf("suggest(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTfvars(t *testing.T) {
+	vars := []HCLVar{
+		{Name: "region", VarType: "string", Required: true},
+		{Name: "zones", VarType: "list(string)", Required: true},
+		{Name: "size", VarType: "number", Default: int64(1), Nullable: true},
+		{Name: "name", VarType: "string", Required: true},
+		{Name: "tags", VarType: "map(string)", Default: map[string]interface{}{}},
+	}
+	dir := writeModule(t, map[string]string{
+		"a.tfvars": `
+regoin = "eu-west-1"
+zones  = "eu-west-1a"
+size   = null
+`,
+		"b.tfvars.json": `{"name": null, "tags": null, "region": "eu-west-1"}`,
+	})
+	var files []*tfvarsFile
+	for _, name := range []string{"a.tfvars", "b.tfvars.json"} {
+		f, err := readTfvars(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("readTfvars(%s): %s", name, err)
+		}
+		files = append(files, f)
+	}
+
+	var got []string
+	for _, issue := range validateTfvars(vars, files) {
+		rel, _ := filepath.Rel(dir, issue.Path)
+		issue.Path = rel
+		got = append(got, issue.String())
+	}
+	want := []string{
+		`a.tfvars:2:1: variable "regoin" is not declared by the module, did you mean "region"?`,
+		`a.tfvars:3:1: zones: expected list(string), got string`,
+		`b.tfvars.json:1:2: name: variable is not nullable`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("validateTfvars = %q, want %q", got, want)
+	}
+}
+
+func TestValidateTfvarsMissing(t *testing.T) {
+	vars := []HCLVar{
+		{Name: "region", VarType: "string", Required: true},
+		{Name: "size", VarType: "number", Default: int64(1)},
+		{Name: "name", VarType: "string", Required: true},
+	}
+	files := []*tfvarsFile{
+		{Path: "a.tfvars", Entries: []tfvarsEntry{{Name: "region", Val: "eu-west-1"}}},
+		{Path: "b.tfvars"},
+	}
+	var got []string
+	for _, issue := range validateTfvars(vars, files) {
+		got = append(got, issue.String())
+	}
+	want := []string{`b.tfvars: missing required variable "name"`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("validateTfvars = %q, want %q", got, want)
+	}
+}
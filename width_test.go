@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestTextWidth(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"name", 4},
+		{"`aws_vpc.this`", 14},
+		{"héllo", 5},
+		{"he\u0301llo", 5},
+		{"日本語", 6},
+		{"한국어 text", 11},
+		{"ｆｕｌｌ", 8},
+		{"ok ✅", 5},
+		{"🚀 launch", 9},
+		{"a\u200bb", 2},
+	}
+	for _, tt := range tests {
+		if got := textWidth(tt.s); got != tt.want {
+			t.Errorf("textWidth(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}